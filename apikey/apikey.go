@@ -0,0 +1,102 @@
+package apikey
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// Set is a set of API keys, each belonging to a principal.
+//
+// A Set is safe for concurrent use.
+type Set struct {
+	// key is the HMAC key used to hash API keys.
+	key [32]byte
+	// macs[i] is the HMAC of the API key belonging to
+	// principals[i].
+	macs       [][sha256.Size]byte
+	principals []string
+}
+
+// NewSet creates a Set from keys, which maps API keys to their
+// principals.
+func NewSet(keys map[string]string) (*Set, error) {
+	s := &Set{
+		macs:       make([][sha256.Size]byte, 0, len(keys)),
+		principals: make([]string, 0, len(keys)),
+	}
+	if _, err := rand.Read(s.key[:]); err != nil {
+		return nil, err
+	}
+	for k, p := range keys {
+		if k == "" {
+			return nil, errors.New("apikey: empty API key")
+		}
+		s.macs = append(s.macs, s.mac(k))
+		s.principals = append(s.principals, p)
+	}
+	return s, nil
+}
+
+func (s *Set) mac(key string) (sum [sha256.Size]byte) {
+	h := hmac.New(sha256.New, s.key[:])
+	h.Write([]byte(key))
+	h.Sum(sum[:0])
+	return
+}
+
+// Lookup returns the principal that owns key.
+//
+// Lookup compares key against every key in the set, so the
+// time it takes depends only on the length of key and the size
+// of the set.
+func (s *Set) Lookup(key string) (principal string, ok bool) {
+	if key == "" {
+		return "", false
+	}
+	mac := s.mac(key)
+	idx := 0
+	found := 0
+	for i := range s.macs {
+		eq := subtle.ConstantTimeCompare(mac[:], s.macs[i][:])
+		idx = subtle.ConstantTimeSelect(eq, i, idx)
+		found |= eq
+	}
+	if found == 0 {
+		return "", false
+	}
+	return s.principals[idx], true
+}
+
+type ctxKey struct{}
+
+// Principal returns the principal stored in ctx by Middleware.
+func Principal(ctx context.Context) (string, bool) {
+	p, ok := ctx.Value(ctxKey{}).(string)
+	return p, ok
+}
+
+// Middleware returns a handler that authenticates requests
+// using the API key in the named header.
+//
+// If the key belongs to a principal in s, the request is passed
+// to next with the principal stored in its context (see
+// Principal). Otherwise, Middleware responds with
+// http.StatusUnauthorized.
+func Middleware(s *Set, header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := s.Lookup(r.Header.Get(header))
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusUnauthorized),
+				http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ctxKey{}, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testKeys = map[string]string{
+	"key-alice":   "alice",
+	"key-bob":     "bob",
+	"longer-key3": "carol",
+}
+
+func TestLookup(t *testing.T) {
+	s, err := NewSet(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range testKeys {
+		got, ok := s.Lookup(k)
+		if !ok || got != want {
+			t.Errorf("Lookup(%q) = (%q, %t), expected (%q, true)", k, got, ok, want)
+		}
+	}
+	for _, k := range []string{"", "key-", "key-alic", "key-alicee", "KEY-ALICE"} {
+		if p, ok := s.Lookup(k); ok {
+			t.Errorf("Lookup(%q) = (%q, true), expected failure", k, p)
+		}
+	}
+}
+
+func TestNewSetEmptyKey(t *testing.T) {
+	if _, err := NewSet(map[string]string{"": "nobody"}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	s, err := NewSet(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Middleware(s, "X-API-Key", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := Principal(r.Context())
+		if !ok {
+			t.Error("missing principal")
+		}
+		io.WriteString(w, p)
+	}))
+
+	for _, tc := range []struct {
+		key  string
+		code int
+		body string
+	}{
+		{"key-bob", http.StatusOK, "bob"},
+		{"key-bo", http.StatusUnauthorized, ""},
+		{"", http.StatusUnauthorized, ""},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.key != "" {
+			req.Header.Set("X-API-Key", tc.key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("%q: expected %d, got %d", tc.key, tc.code, rec.Code)
+		}
+		if tc.code == http.StatusOK && rec.Body.String() != tc.body {
+			t.Errorf("%q: expected %q, got %q", tc.key, tc.body, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package apikey implements constant-time API key checking for
+// HTTP servers.
+//
+// Presented keys are compared against every configured key
+// using double HMAC: both sides are hashed under a random
+// per-Set key before comparison, so neither the length of
+// a configured key nor the position at which a guess diverges
+// from it affects timing.
+package apikey
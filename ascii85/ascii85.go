@@ -0,0 +1,395 @@
+package ascii85
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+	"github.com/ericlagergren/subtle/internal/base85"
+)
+
+// a85 maps the digits [0, 85) to the characters ['!', 'u'].
+var a85 = func() *alphabet.Alphabet {
+	var chars [85]byte
+	for i := range chars {
+		chars[i] = '!' + byte(i)
+	}
+	return alphabet.New(string(chars[:]))
+}()
+
+// MaxEncodedLen returns the maximum length of an encoding of n
+// source bytes.
+func MaxEncodedLen(n int) int { return (n + 3) / 4 * 5 }
+
+// Encode encodes src into at most MaxEncodedLen(len(src)) bytes
+// of dst, returning the actual number of bytes written.
+//
+// The encoding handles 4-byte chunks, using a special encoding
+// for the last fragment, so Encode is not appropriate for use on
+// individual blocks of a large data stream. Use NewEncoder
+// instead.
+//
+// Often, ascii85-encoded data is wrapped in <~ and ~> symbols.
+// Encode does not add these. See EncodeToStringFramed.
+//
+// Encode runs in constant time for the length of src, except
+// that each group of four zero bytes shortens the output.
+func Encode(dst, src []byte) int {
+	n := 0
+	for len(src) >= 4 {
+		v := binary.BigEndian.Uint32(src)
+		base85.EncodeBlock(a85, dst[n:], v)
+		// Zero (!!!!!) shortens to z.
+		z := subtle.ConstantTimeEq(int32(v), 0)
+		dst[n] = byte(subtle.ConstantTimeSelect(z, 'z', int(dst[n])))
+		n += subtle.ConstantTimeSelect(z, 1, 5)
+		src = src[4:]
+	}
+	if len(src) > 0 {
+		// Encode the final fragment as if it were padded with
+		// zeros and discard the low digits.
+		var buf [4]byte
+		copy(buf[:], src)
+		base85.EncodeBlock(a85, dst[n:], binary.BigEndian.Uint32(buf[:]))
+		subtle.Wipe(buf[:])
+		n += len(src) + 1
+	}
+	return n
+}
+
+// AppendEncode appends the ascii85 encoding of src to dst and
+// returns the extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, MaxEncodedLen(len(src)))
+	n := Encode(out, src)
+	return ret[:len(dst)+n]
+}
+
+// EncodeToString returns the ascii85 encoding of src.
+func EncodeToString(src []byte) string {
+	buf := AppendEncode(nil, src)
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s
+}
+
+// EncodeToStringFramed returns the ascii85 encoding of src
+// wrapped in <~ and ~> symbols.
+func EncodeToStringFramed(src []byte) string {
+	buf := make([]byte, 0, 2+MaxEncodedLen(len(src))+2)
+	buf = append(buf, "<~"...)
+	buf = AppendEncode(buf, src)
+	buf = append(buf, "~>"...)
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s
+}
+
+// CorruptInputError is returned when the input is malformed.
+// Its value is the offset of the first invalid byte.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal ascii85 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// ErrFrame is returned by DecodeStringFramed when the input is
+// not terminated by ~>.
+var ErrFrame = errors.New("ascii85: missing ~> terminator")
+
+// Decode decodes src into dst, returning both the number of
+// bytes written to dst and the number consumed from src.
+//
+// If src contains invalid ascii85 data, Decode returns zero and
+// a CorruptInputError. Unlike encoding/ascii85, a group whose
+// value does not fit in 32 bits is invalid. Decode ignores
+// space and control characters in src. Often, ascii85-encoded
+// data is wrapped in <~ and ~> symbols. Decode expects these to
+// have been stripped by the caller. See DecodeStringFramed.
+//
+// If flush is true, Decode assumes that src represents the end
+// of the input stream and processes it completely rather than
+// wait for the completion of another 32-bit block.
+//
+// Decode stops when dst does not have room for another 4-byte
+// group, like encoding/ascii85. Otherwise, it runs in constant
+// time for the length of src.
+func Decode(dst, src []byte, flush bool) (ndst, nsrc int, err error) {
+	// limit is the number of digits that fit in dst.
+	limit := len(dst) / 4 * 5
+
+	// Whitespace is skipped and 'z' counts as five digits, so
+	// j%5 is the number of digits in the current group.
+	var (
+		acc     uint64
+		j       int
+		bad     alphabet.FirstError
+		stopped bool
+	)
+	for i, c := range src {
+		if j >= limit {
+			stopped = true
+			break
+		}
+		isSpace := subtle.ConstantTimeByteLessOrEq(c, ' ')
+		isDigit := subtle.ConstantTimeByteLessOrEq('!', c) &
+			subtle.ConstantTimeByteLessOrEq(c, 'u')
+		isZ := subtle.ConstantTimeByteEq(c, 'z') &
+			subtle.ConstantTimeEq(int32(j%5), 0)
+
+		d := uint64(c - '!')
+		m := -uint64(isDigit)
+		acc = (acc*85+d)&m | acc&^m
+		j += isDigit + 5*isZ
+
+		// A group is complete if this character was a digit or
+		// 'z' and j is a multiple of five.
+		done := (isDigit | isZ) & subtle.ConstantTimeEq(int32(j%5), 0)
+		ov := int(acc>>32) & done
+
+		// dst has room for the current group, so write it
+		// unconditionally and only advance past it once it is
+		// complete.
+		binary.BigEndian.PutUint32(dst[ndst:], uint32(acc))
+		ndst += 4 * done
+		nsrc = subtle.ConstantTimeSelect(done, i+1, nsrc)
+		acc &^= -uint64(done)
+
+		bad.Check((isSpace|isDigit|isZ)^1|ov, i)
+	}
+	// Remove any partial group.
+	if rest := dst[ndst:]; len(rest) >= 4 {
+		subtle.Wipe(rest[:4])
+	}
+	if bad.Failed() != 0 {
+		subtle.Wipe(dst[:ndst])
+		return 0, 0, CorruptInputError(bad.Index())
+	}
+	if !flush || stopped {
+		return ndst, nsrc, nil
+	}
+
+	nsrc = len(src)
+	nb := j % 5
+	if nb == 0 {
+		return ndst, nsrc, nil
+	}
+	// The number of output bytes in the last fragment is the
+	// number of leftover digits - 1: the extra digit provides
+	// enough bits to cover the inefficiency of the encoding
+	// for the block.
+	if nb == 1 {
+		subtle.Wipe(dst[:ndst])
+		return 0, 0, CorruptInputError(len(src))
+	}
+	// The short encoding truncated the output value, so assume
+	// the worst case values (digit 84) in order to ensure that
+	// the top bits are correct.
+	for i := nb; i < 5; i++ {
+		acc = acc*85 + 84
+	}
+	if acc>>32 != 0 {
+		subtle.Wipe(dst[:ndst])
+		return 0, 0, CorruptInputError(len(src))
+	}
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(acc))
+	ndst += copy(dst[ndst:ndst+nb-1], buf[:])
+	subtle.Wipe(buf[:])
+	return ndst, nsrc, nil
+}
+
+// decodedLen returns the maximum length of the bytes
+// represented by n ascii85 characters.
+func decodedLen(n int) int {
+	// Each 'z' decodes to four bytes.
+	return 4 * n
+}
+
+// AppendDecode appends the bytes represented by the ascii85
+// data src to dst and returns the extended buffer.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, decodedLen(len(src))+4)
+	n, _, err := Decode(out, src, true)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by the ascii85
+// string s.
+func DecodeString(s string) ([]byte, error) {
+	return AppendDecode(nil, []byte(s))
+}
+
+// DecodeStringFramed is like DecodeString, but s must be
+// terminated by ~>. The leading <~ is optional, as it is in the
+// PDF ASCII85Decode filter.
+func DecodeStringFramed(s string) ([]byte, error) {
+	off := 0
+	if len(s) >= 2 && s[:2] == "<~" {
+		off = 2
+	}
+	if len(s)-off < 2 || s[len(s)-2:] != "~>" {
+		return nil, ErrFrame
+	}
+	b, err := DecodeString(s[off : len(s)-2])
+	if e, ok := err.(CorruptInputError); ok {
+		err = e + CorruptInputError(off)
+	}
+	return b, err
+}
+
+// NewEncoder returns a new ascii85 stream encoder. Data written
+// to the returned writer will be encoded and then written to w.
+// Ascii85 encodings operate in 32-bit blocks; when finished
+// writing, the caller must Close the returned encoder to flush
+// any trailing partial block.
+func NewEncoder(w io.Writer) io.WriteCloser { return &encoder{w: w} }
+
+type encoder struct {
+	err  error
+	w    io.Writer
+	buf  [4]byte    // buffered data waiting to be encoded
+	nbuf int        // number of bytes in buf
+	out  [1024]byte // output buffer
+}
+
+func (e *encoder) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	// Leading fringe.
+	if e.nbuf > 0 {
+		var i int
+		for i = 0; i < len(p) && e.nbuf < 4; i++ {
+			e.buf[e.nbuf] = p[i]
+			e.nbuf++
+		}
+		n += i
+		p = p[i:]
+		if e.nbuf < 4 {
+			return
+		}
+		nout := Encode(e.out[:], e.buf[:])
+		subtle.Wipe(e.buf[:])
+		e.nbuf = 0
+		if e.err = e.write(nout); e.err != nil {
+			return n, e.err
+		}
+	}
+
+	// Large interior chunks.
+	for len(p) >= 4 {
+		nn := len(e.out) / 5 * 4
+		if nn > len(p) {
+			nn = len(p)
+		}
+		nn -= nn % 4
+		nout := Encode(e.out[:], p[:nn])
+		if e.err = e.write(nout); e.err != nil {
+			return n, e.err
+		}
+		n += nn
+		p = p[nn:]
+	}
+
+	// Trailing fringe.
+	copy(e.buf[:], p)
+	e.nbuf = len(p)
+	n += len(p)
+	return
+}
+
+// write writes e.out[:n] to the underlying writer and wipes it.
+func (e *encoder) write(n int) error {
+	_, err := e.w.Write(e.out[:n])
+	subtle.Wipe(e.out[:n])
+	return err
+}
+
+// Close flushes any pending output from the encoder.
+// It is an error to call Write after calling Close.
+func (e *encoder) Close() error {
+	// If there's anything left in the buffer, flush it out.
+	if e.err == nil && e.nbuf > 0 {
+		nout := Encode(e.out[:], e.buf[:e.nbuf])
+		subtle.Wipe(e.buf[:])
+		e.nbuf = 0
+		e.err = e.write(nout)
+	}
+	return e.err
+}
+
+// NewDecoder constructs a new ascii85 stream decoder.
+func NewDecoder(r io.Reader) io.Reader { return &decoder{r: r} }
+
+type decoder struct {
+	err     error
+	readErr error
+	r       io.Reader
+	buf     [1024]byte // leftover input
+	nbuf    int
+	out     []byte // leftover decoded output
+	outbuf  [1024]byte
+}
+
+func (d *decoder) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	for {
+		// Copy leftover output from last decode.
+		if len(d.out) > 0 {
+			n = copy(p, d.out)
+			subtle.Wipe(d.out[:n])
+			d.out = d.out[n:]
+			return
+		}
+
+		// Decode leftover input from last read.
+		if d.nbuf > 0 {
+			var ndst, nsrc int
+			ndst, nsrc, d.err = Decode(d.outbuf[:], d.buf[:d.nbuf], d.readErr != nil)
+			if ndst > 0 {
+				d.out = d.outbuf[:ndst]
+				d.nbuf = copy(d.buf[:], d.buf[nsrc:d.nbuf])
+				continue // copy out and return
+			}
+			if d.err == nil {
+				// Special case: input buffer is mostly
+				// filled with non-data bytes. Filter out such
+				// bytes to make room for more input, without
+				// branching on the data.
+				off := 0
+				for i := 0; i < d.nbuf; i++ {
+					c := d.buf[i]
+					d.buf[off] = c
+					off += subtle.ConstantTimeByteGreater(c, ' ')
+				}
+				d.nbuf = off
+			}
+		}
+
+		// Out of input, out of decoded output. Check errors.
+		if d.err != nil {
+			subtle.Wipe(d.buf[:])
+			return 0, d.err
+		}
+		if d.readErr != nil {
+			d.err = d.readErr
+			return 0, d.err
+		}
+
+		// Read more data.
+		var nn int
+		nn, d.readErr = d.r.Read(d.buf[d.nbuf:])
+		d.nbuf += nn
+	}
+}
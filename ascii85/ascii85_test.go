@@ -0,0 +1,190 @@
+package ascii85
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+var pairs = []struct {
+	decoded, encoded string
+}{
+	{"", ""},
+	// Wikipedia example.
+	{"Man ", "9jqo^"},
+	{"sure.", "F*2M7/c"},
+	{"\x00\x00\x00\x00", "z"},
+	{"\x00\x00\x00\x00\x00", "z!!"},
+	{"\x00\x00\x00", "!!!!"},
+	{"\xff\xff\xff\xff", "s8W-!"},
+	{"\x00\x00\x00\x01\x00\x00\x00\x00", "!!!!\"z"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, p := range pairs {
+		got := EncodeToString([]byte(p.decoded))
+		if got != p.encoded {
+			t.Fatalf("EncodeToString(%q): expected %q, got %q", p.decoded, p.encoded, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, p := range pairs {
+		got, err := DecodeString(p.encoded)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", p.encoded, err)
+		}
+		if string(got) != p.decoded {
+			t.Fatalf("DecodeString(%q): expected %q, got %q", p.encoded, p.decoded, got)
+		}
+	}
+}
+
+func TestFramed(t *testing.T) {
+	const s = "<~9jqo^F*2M7/c~>"
+	if got := EncodeToStringFramed([]byte("Man sure.")); got != s {
+		t.Fatalf("expected %q, got %q", s, got)
+	}
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{s, nil},
+		{s[2:], nil},
+		{"<~ 9jqo^\nF*2M7/c ~>", nil},
+		{"<~", ErrFrame},
+		{"~", ErrFrame},
+		{s[:len(s)-1], ErrFrame},
+		{"<~9jqo^~F*2M7/c~>", CorruptInputError(7)},
+	} {
+		got, err := DecodeStringFramed(tc.s)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("DecodeStringFramed(%q): expected %v, got %v", tc.s, tc.err, err)
+		}
+		if err == nil && string(got) != "Man sure." {
+			t.Fatalf("DecodeStringFramed(%q): got %q", tc.s, got)
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		src string
+		err error
+	}{
+		{"9jqo^v", CorruptInputError(5)},
+		{"9jqo^F*~M7/c", CorruptInputError(7)},
+		{"9jzqo^", CorruptInputError(2)},
+		{"9jqo^F", CorruptInputError(6)},
+		{"9jqo^s8W-\"", CorruptInputError(9)},
+		{"uuuuu", CorruptInputError(4)},
+		{"9jqo^s9", CorruptInputError(7)},
+	} {
+		dst := make([]byte, 4*len(tc.src)+4)
+		n, _, err := Decode(dst, []byte(tc.src), true)
+		if n != 0 || !errors.Is(err, tc.err) {
+			t.Fatalf("Decode(%q): expected (0, %v), got (%d, %v)", tc.src, tc.err, n, err)
+		}
+		if !bytes.Equal(dst, make([]byte, len(dst))) {
+			t.Fatalf("Decode(%q): dst not wiped: %x", tc.src, dst)
+		}
+	}
+}
+
+// randomInput returns random data with runs of zeros.
+func randomInput(rng *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	rng.Read(b)
+	for i := 0; i+4 <= n; i += 4 {
+		if rng.Intn(4) == 0 {
+			copy(b[i:i+4], make([]byte, 4))
+		}
+	}
+	return b
+}
+
+// addSpace inserts random whitespace into s.
+func addSpace(rng *rand.Rand, s []byte) []byte {
+	var out []byte
+	for _, c := range s {
+		if rng.Intn(4) == 0 {
+			out = append(out, " \t\r\n\x00"[rng.Intn(5)])
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// TestStdlib compares Encode and Decode against encoding/ascii85.
+func TestStdlib(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		for iter := 0; iter < 10; iter++ {
+			src := randomInput(rng, n)
+			want := make([]byte, ascii85.MaxEncodedLen(n))
+			want = want[:ascii85.Encode(want, src)]
+			got := make([]byte, MaxEncodedLen(n))
+			got = got[:Encode(got, src)]
+			if !bytes.Equal(got, want) {
+				t.Fatalf("Encode(%x): expected %q, got %q", src, want, got)
+			}
+
+			enc := addSpace(rng, want)
+			for _, flush := range []bool{false, true} {
+				for _, size := range []int{4, 7, 16, len(src) + 4} {
+					want := make([]byte, size)
+					wantDst, wantSrc, err := ascii85.Decode(want, enc, flush)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got := make([]byte, size)
+					gotDst, gotSrc, err := Decode(got, enc, flush)
+					if err != nil {
+						t.Fatalf("Decode(%q): %v", enc, err)
+					}
+					if gotDst != wantDst || gotSrc != wantSrc ||
+						!bytes.Equal(got[:gotDst], want[:wantDst]) {
+						t.Fatalf("Decode(%q, %d, %t): expected (%d, %d, %x), got (%d, %d, %x)",
+							enc, size, flush, wantDst, wantSrc, want[:wantDst],
+							gotDst, gotSrc, got[:gotDst])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestStreams(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 3, 4, 5, 1000, 5000} {
+		src := randomInput(rng, n)
+		var buf bytes.Buffer
+		w := NewEncoder(&buf)
+		for p := src; len(p) > 0; {
+			k := rng.Intn(len(p)) + 1
+			if _, err := w.Write(p[:k]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[k:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := EncodeToString(src); buf.String() != want {
+			t.Fatalf("%d: expected %q, got %q", n, want, buf.String())
+		}
+
+		enc := addSpace(rng, buf.Bytes())
+		got, err := io.ReadAll(NewDecoder(bytes.NewReader(enc)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, src) {
+			t.Fatalf("%d: expected %x, got %x", n, src, got)
+		}
+	}
+}
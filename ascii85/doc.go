@@ -0,0 +1,13 @@
+// Package ascii85 implements constant-time ascii85 encoding, as
+// used by the btoa tool and Adobe's PostScript and PDF document
+// formats.
+//
+// The API mirrors encoding/ascii85, but every character is
+// translated without secret-dependent branches or table
+// lookups, so it is suitable for tooling that embeds signing
+// material in documents.
+//
+// The 'z' shortcut for a group of four zero bytes is inherent
+// to the format, so the length of an encoding reveals the
+// number of such groups.
+package ascii85
@@ -0,0 +1,549 @@
+package base32
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const (
+	// StdPadding is the standard padding character.
+	StdPadding rune = '='
+	// NoPadding disables padding.
+	NoPadding rune = -1
+)
+
+const (
+	encodeStd = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	encodeHex = "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+)
+
+// An Encoding is a radix 32 encoding/decoding scheme, defined by
+// a 32-character alphabet.
+//
+// Every Encoding method runs in constant time for the length of
+// its input.
+type Encoding struct {
+	alpha   *alphabet.Alphabet
+	padChar rune
+	strict  bool
+	hyphens bool               // ignore hyphens when decoding
+	check   *alphabet.Alphabet // Crockford check symbols, if any
+}
+
+// StdEncoding is the standard base32 encoding, as defined in
+// RFC 4648.
+var StdEncoding = newEncoding(encodeStd)
+
+// HexEncoding is the “Extended Hex Alphabet” defined in RFC
+// 4648. It is typically used in DNS.
+var HexEncoding = newEncoding(encodeHex)
+
+// NewEncoding returns a new Encoding defined by the given
+// alphabet, which must be a 32-byte string of unique bytes that
+// does not contain CR / LF ('\r', '\n').
+//
+// The encoding is padded with StdPadding, unless the alphabet
+// contains StdPadding, in which case it has no padding. Use
+// WithPadding to change the padding.
+//
+// Alphabets made of a few ranges of consecutive characters, like
+// "A-Z" and "2-7" in StdEncoding, are the fastest to encode and
+// decode.
+func NewEncoding(encoder string) *Encoding {
+	if len(encoder) != 32 {
+		panic("base32: encoding alphabet is not 32-bytes long")
+	}
+	var seen [256]bool
+	for i := 0; i < len(encoder); i++ {
+		c := encoder[i]
+		if c == '\n' || c == '\r' {
+			panic("base32: encoding alphabet contains newline character")
+		}
+		if seen[c] {
+			panic("base32: encoding alphabet includes duplicate symbols")
+		}
+		seen[c] = true
+	}
+	enc := newEncoding(encoder)
+	if seen[StdPadding] {
+		enc.padChar = NoPadding
+	}
+	return enc
+}
+
+func newEncoding(chars string) *Encoding {
+	return &Encoding{
+		alpha:   alphabet.New(chars),
+		padChar: StdPadding,
+	}
+}
+
+// WithPadding creates a new encoding identical to enc except
+// with a specified padding character, or NoPadding to disable
+// padding.
+//
+// WithPadding panics if padding is a newline character, is part
+// of the encoding's alphabet, is a negative rune other than
+// NoPadding, or is a rune larger than '\xff'.
+func (enc Encoding) WithPadding(padding rune) *Encoding {
+	if padding < NoPadding || padding == '\r' || padding == '\n' || padding > 0xff {
+		panic("base32: invalid padding")
+	}
+	if padding != NoPadding && enc.alpha.Contains(byte(padding)) {
+		panic("base32: padding contained in alphabet")
+	}
+	enc.padChar = padding
+	return &enc
+}
+
+// Strict creates a new encoding identical to enc except with
+// strict decoding enabled. In this mode, the decoder requires
+// that trailing padding bits are zero, as described in RFC 4648
+// section 3.5.
+func (enc Encoding) Strict() *Encoding {
+	enc.strict = true
+	return &enc
+}
+
+// CorruptInputError is returned when the input is malformed.
+// Its value is the offset of the first invalid byte.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal base32 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// EncodedLen returns the length in bytes of the base32 encoding
+// of an input buffer of length n.
+func (enc *Encoding) EncodedLen(n int) int {
+	if enc.check != nil {
+		return enc.encodedLen(n) + 1
+	}
+	return enc.encodedLen(n)
+}
+
+// encodedLen returns the length of the encoding of n bytes,
+// excluding the check symbol.
+func (enc *Encoding) encodedLen(n int) int {
+	if enc.padChar == NoPadding {
+		return n/5*8 + (n%5*8+4)/5
+	}
+	return (n + 4) / 5 * 8
+}
+
+// DecodedLen returns the maximum length in bytes of the decoded
+// data corresponding to n bytes of base32-encoded data.
+func (enc *Encoding) DecodedLen(n int) int {
+	if enc.check != nil && n > 0 {
+		n--
+	}
+	if enc.padChar == NoPadding {
+		return n/8*5 + n%8*5/8
+	}
+	return n / 8 * 5
+}
+
+// Encode encodes src using the encoding enc, writing
+// EncodedLen(len(src)) bytes to dst.
+func (enc *Encoding) Encode(dst, src []byte) {
+	enc.encode(dst, src)
+	if enc.check != nil {
+		sum := checkFinish(checkBytes(0, src), int64(len(src)))
+		dst[enc.encodedLen(len(src))] = enc.check.Encode(byte(sum))
+	}
+}
+
+// encode encodes src, excluding the check symbol.
+func (enc *Encoding) encode(dst, src []byte) {
+	for len(src) >= 5 {
+		enc.encodeBlock(dst, src[:5])
+		src = src[5:]
+		dst = dst[8:]
+	}
+	if len(src) == 0 {
+		return
+	}
+
+	var tmp [5]byte
+	copy(tmp[:], src)
+	var out [8]byte
+	enc.encodeBlock(out[:], tmp[:])
+	n := (len(src)*8 + 4) / 5
+	copy(dst, out[:n])
+	if enc.padChar != NoPadding {
+		for i := n; i < 8; i++ {
+			dst[i] = byte(enc.padChar)
+		}
+	}
+	subtle.Wipe(tmp[:])
+	subtle.Wipe(out[:])
+}
+
+// encodeBlock encodes the 5 bytes in src into 8 characters.
+func (enc *Encoding) encodeBlock(dst, src []byte) {
+	x := uint64(src[0])<<32 | uint64(src[1])<<24 |
+		uint64(src[2])<<16 | uint64(src[3])<<8 | uint64(src[4])
+	for i := 0; i < 8; i++ {
+		dst[i] = enc.alpha.Encode(byte(x>>(35-5*i)) & 0x1f)
+	}
+}
+
+// AppendEncode appends the base32 encoding of src to dst and
+// returns the extended buffer.
+func (enc *Encoding) AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, enc.EncodedLen(len(src)))
+	enc.Encode(out, src)
+	return ret
+}
+
+// EncodeToString returns the base32 encoding of src.
+func (enc *Encoding) EncodeToString(src []byte) string {
+	buf := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(buf, src)
+	return string(buf)
+}
+
+// Decode decodes src using the encoding enc. It writes at most
+// DecodedLen(len(src)) bytes to dst and returns the number of
+// bytes written. If src contains invalid base32 data, it will
+// return the number of bytes successfully written and
+// CorruptInputError.
+//
+// src may alias dst if both begin at the same address.
+func (enc *Encoding) Decode(dst, src []byte) (int, error) {
+	if enc.hyphens {
+		var arr [128]byte
+		buf := arr[:]
+		if len(src) > len(arr) {
+			buf = make([]byte, len(src))
+		}
+		defer subtle.Wipe(buf)
+		n := copy(buf, src)
+		src = buf[:compactHyphens(buf[:n])]
+	}
+	var sum uint
+	return enc.decodeCheck(dst, src, 0, true, &sum)
+}
+
+// decodeCheck is like decode, but also handles the check
+// symbol, if any.
+//
+// sum is the check sum of the characters before src. If final is
+// true, the last character of src is the check symbol.
+func (enc *Encoding) decodeCheck(dst, src []byte, off int64, final bool, sum *uint) (int, error) {
+	if enc.check == nil {
+		return enc.decode(dst, src, off, final)
+	}
+
+	var c byte
+	if final {
+		if len(src) == 0 {
+			return 0, CorruptInputError(off)
+		}
+		c = src[len(src)-1]
+		src = src[:len(src)-1]
+	}
+	// Compute the check sum before decoding in case src and
+	// dst alias.
+	*sum = enc.checkChars(*sum, src)
+	n, err := enc.decode(dst, src, off, final)
+	if err != nil || !final {
+		return n, err
+	}
+	v, ok := enc.check.Decode(c)
+	if ok&subtle.ConstantTimeByteEq(v, byte(*sum)) != 1 {
+		return n, CorruptInputError(off + int64(len(src)))
+	}
+	return n, nil
+}
+
+// decode implements Decode.
+//
+// off is added to the offset in CorruptInputError. If final is
+// false, src is not the end of the input, so it must be
+// a multiple of 8 characters without padding.
+func (enc *Encoding) decode(dst, src []byte, off int64, final bool) (int, error) {
+	// m is the number of data characters.
+	m := len(src)
+	var lenErr bool
+	if final && enc.padChar != NoPadding {
+		if len(src)%8 != 0 {
+			return 0, CorruptInputError(off + int64(len(src)/8*8))
+		}
+		if len(src) > 0 {
+			p := enc.countPadding(src[len(src)-8:])
+			m -= p
+			lenErr = p == 2 || p == 5 || p > 6
+		}
+	} else {
+		r := m % 8
+		lenErr = r == 1 || r == 3 || r == 6 || (!final && r != 0)
+	}
+
+	// bad records the offset of the first invalid character.
+	var bad alphabet.FirstError
+	n := 0
+	for i := 0; i < m; i += 8 {
+		j := m - i
+		if j > 8 {
+			j = 8
+		}
+
+		// Read the entire block before writing to dst in case
+		// src and dst alias.
+		var x uint64
+		for k := 0; k < j; k++ {
+			v, ok := enc.alpha.Decode(src[i+k])
+			bad.Check(ok^1, i+k)
+			x |= uint64(v) << (35 - 5*k)
+		}
+		w := j * 5 / 8
+
+		// In strict mode, the bits of the final character that
+		// do not fit into a whole byte must be zero.
+		if enc.strict && j < 8 {
+			mask := uint64(1)<<(40-8*w) - 1
+			t := x & mask
+			bad.Check(int((t|-t)>>63), i+j-1)
+		}
+
+		for k := 0; k < w; k++ {
+			dst[n+k] = byte(x >> (32 - 8*k))
+		}
+		n += w
+	}
+
+	if bad.Failed() != 0 {
+		return bad.Index() / 8 * 5, CorruptInputError(off + int64(bad.Index()))
+	}
+	if lenErr {
+		return m / 8 * 5, CorruptInputError(off + int64(m))
+	}
+	return n, nil
+}
+
+// countPadding returns the number of trailing padding
+// characters in the final block b.
+//
+// countPadding runs in constant time.
+func (enc *Encoding) countPadding(b []byte) int {
+	pad := byte(enc.padChar)
+	n := 0
+	run := 1
+	for k := len(b) - 1; k >= 0; k-- {
+		run &= subtle.ConstantTimeByteEq(b[k], pad)
+		n += run
+	}
+	return n
+}
+
+// AppendDecode appends the base32 decoded src to dst and returns
+// the extended buffer. If the input is malformed, it returns the
+// partially decoded src and an error.
+func (enc *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, enc.DecodedLen(len(src)))
+	n, err := enc.Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by the base32
+// string s.
+func (enc *Encoding) DecodeString(s string) ([]byte, error) {
+	buf := []byte(s)
+	n, err := enc.Decode(buf, buf)
+	return buf[:n], err
+}
+
+// bufferSize is the number of characters buffered by the
+// encoder and decoder. It is a multiple of 8.
+const bufferSize = 1024
+
+type encoder struct {
+	enc  *Encoding
+	w    io.Writer
+	err  error
+	buf  [5]byte // buffered data waiting to be encoded
+	nbuf int     // number of bytes in buf
+	out  [bufferSize]byte
+
+	// For encodings with a check symbol.
+	sum   uint  // check sum of the bytes written so far
+	total int64 // number of bytes written so far
+}
+
+var errEncoderClosed = errors.New("base32: encoder closed")
+
+// NewEncoder returns a new base32 stream encoder. Data written
+// to the returned writer will be encoded using enc and then
+// written to w. Base32 encodings operate in 5-byte blocks; when
+// finished writing, the caller must Close the returned encoder
+// to flush any partially written blocks.
+//
+// Closing the encoder also wipes its internal buffers. It does
+// not close w.
+func NewEncoder(enc *Encoding, w io.Writer) io.WriteCloser {
+	return &encoder{enc: enc, w: w}
+}
+
+func (e *encoder) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.enc.check != nil {
+		e.sum = checkBytes(e.sum, p)
+		e.total += int64(len(p))
+	}
+
+	// Leading fringe.
+	if e.nbuf > 0 {
+		var i int
+		for i = 0; i < len(p) && e.nbuf < 5; i++ {
+			e.buf[e.nbuf] = p[i]
+			e.nbuf++
+		}
+		n += i
+		p = p[i:]
+		if e.nbuf < 5 {
+			return n, nil
+		}
+		e.enc.encode(e.out[:], e.buf[:])
+		if _, e.err = e.w.Write(e.out[:8]); e.err != nil {
+			return n, e.err
+		}
+		e.nbuf = 0
+	}
+
+	// Large interior chunks.
+	for len(p) >= 5 {
+		nn := len(e.out) / 8 * 5
+		if nn > len(p) {
+			nn = len(p) / 5 * 5
+		}
+		e.enc.encode(e.out[:], p[:nn])
+		if _, e.err = e.w.Write(e.out[:nn/5*8]); e.err != nil {
+			return n, e.err
+		}
+		n += nn
+		p = p[nn:]
+	}
+
+	// Trailing fringe.
+	e.nbuf = copy(e.buf[:], p)
+	n += len(p)
+	return n, nil
+}
+
+// Close flushes any pending output from the encoder and wipes
+// its internal buffers. Subsequent calls to Write return an
+// error.
+//
+// Close does not close the underlying io.Writer.
+func (e *encoder) Close() error {
+	if e.err == nil && (e.nbuf > 0 || e.enc.check != nil) {
+		e.enc.encode(e.out[:], e.buf[:e.nbuf])
+		n := e.enc.encodedLen(e.nbuf)
+		if e.enc.check != nil {
+			sum := checkFinish(e.sum, e.total)
+			e.out[n] = e.enc.check.Encode(byte(sum))
+			n++
+		}
+		_, e.err = e.w.Write(e.out[:n])
+		e.nbuf = 0
+	}
+	subtle.Wipe(e.buf[:])
+	subtle.Wipe(e.out[:])
+	err := e.err
+	if e.err == nil {
+		e.err = errEncoderClosed
+	}
+	return err
+}
+
+type decoder struct {
+	enc *Encoding
+	r   io.Reader
+	err error
+	off int64  // offset of in in the stream
+	in  []byte // buffered characters (encoded form)
+	out []byte // decoded data waiting to be read
+	arr [bufferSize]byte
+	sum uint // check sum of the characters decoded so far
+}
+
+var errDecoderClosed = errors.New("base32: decoder closed")
+
+// NewDecoder constructs a new base32 stream decoder.
+//
+// The first call to Read that encounters malformed base32 data
+// returns a non-nil error. This means that the decoder does not
+// operate in constant time over the entire stream, but rather
+// for each chunk read from r.
+//
+// Closing the decoder wipes its internal buffer. It does not
+// close r.
+func NewDecoder(enc *Encoding, r io.Reader) io.ReadCloser {
+	return &decoder{enc: enc, r: r}
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		// Refill the buffer, keeping any leftover characters.
+		nc := copy(d.arr[:], d.in)
+		nr, err := d.r.Read(d.arr[nc:])
+		if d.enc.hyphens {
+			nr = compactHyphens(d.arr[nc : nc+nr])
+		}
+		d.in = d.arr[:nc+nr]
+
+		final := err == io.EOF
+		if err != nil && !final {
+			d.err = err
+		}
+
+		// Unless this is the end of the input, hold back at
+		// least one character so that the final block, which
+		// might be padded, is decoded last.
+		k := len(d.in)
+		if !final {
+			k = (k - 1) / 8 * 8
+			if k <= 0 {
+				continue
+			}
+		}
+
+		// Decode in place: the decoded bytes overwrite the
+		// characters they were decoded from.
+		n, derr := d.enc.decodeCheck(d.in, d.in[:k], d.off, final, &d.sum)
+		d.out = d.in[:n]
+		d.in = d.in[k:]
+		d.off += int64(k)
+		if derr != nil {
+			d.in, d.err = nil, derr
+		} else if final {
+			d.err = io.EOF
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// Close wipes the decoder's internal buffer and discards any
+// buffered data. Subsequent calls to Read return an error.
+//
+// Close does not close the underlying io.Reader.
+func (d *decoder) Close() error {
+	subtle.Wipe(d.arr[:])
+	d.in = nil
+	d.out = nil
+	d.err = errDecoderClosed
+	return nil
+}
@@ -0,0 +1,395 @@
+package base32
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type testpair struct {
+	decoded, encoded string
+}
+
+// RFC 4648 test vectors.
+var pairs = []testpair{
+	{"", ""},
+	{"f", "MY======"},
+	{"fo", "MZXQ===="},
+	{"foo", "MZXW6==="},
+	{"foob", "MZXW6YQ="},
+	{"fooba", "MZXW6YTB"},
+	{"foobar", "MZXW6YTBOI======"},
+
+	// Wikipedia examples, converted to base32.
+	{"sure.", "ON2XEZJO"},
+	{"sure", "ON2XEZI="},
+	{"sur", "ON2XE==="},
+	{"su", "ON2Q===="},
+	{"leasure.", "NRSWC43VOJSS4==="},
+	{"easure.", "MVQXG5LSMUXA===="},
+	{"asure.", "MFZXK4TFFY======"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, p := range pairs {
+		got := StdEncoding.EncodeToString([]byte(p.decoded))
+		if got != p.encoded {
+			t.Errorf("Encode(%q): expected %q, got %q", p.decoded, p.encoded, got)
+		}
+		buf := StdEncoding.AppendEncode([]byte("x"), []byte(p.decoded))
+		if string(buf) != "x"+p.encoded {
+			t.Errorf("AppendEncode(%q): expected %q, got %q", p.decoded, "x"+p.encoded, buf)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, p := range pairs {
+		dbuf := make([]byte, StdEncoding.DecodedLen(len(p.encoded)))
+		n, err := StdEncoding.Decode(dbuf, []byte(p.encoded))
+		if err != nil || string(dbuf[:n]) != p.decoded {
+			t.Errorf("Decode(%q) = (%q, %v), want (%q, nil)", p.encoded, dbuf[:n], err, p.decoded)
+		}
+		got, err := StdEncoding.DecodeString(p.encoded)
+		if err != nil || string(got) != p.decoded {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", p.encoded, got, err, p.decoded)
+		}
+		buf, err := StdEncoding.AppendDecode([]byte("x"), []byte(p.encoded))
+		if err != nil || string(buf) != "x"+p.decoded {
+			t.Errorf("AppendDecode(%q) = (%q, %v), want (%q, nil)", p.encoded, buf, err, "x"+p.decoded)
+		}
+	}
+}
+
+func TestNoPadding(t *testing.T) {
+	enc := StdEncoding.WithPadding(NoPadding)
+	for _, p := range pairs {
+		want := strings.TrimRight(p.encoded, "=")
+		if got := enc.EncodeToString([]byte(p.decoded)); got != want {
+			t.Errorf("Encode(%q): expected %q, got %q", p.decoded, want, got)
+		}
+		if n := enc.EncodedLen(len(p.decoded)); n != len(want) {
+			t.Errorf("EncodedLen(%d): expected %d, got %d", len(p.decoded), len(want), n)
+		}
+		got, err := enc.DecodeString(want)
+		if err != nil || string(got) != p.decoded {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", want, got, err, p.decoded)
+		}
+		if _, err := enc.DecodeString(p.encoded); p.encoded != want && err == nil {
+			t.Errorf("DecodeString(%q): expected an error", p.encoded)
+		}
+	}
+}
+
+func TestCustomPadding(t *testing.T) {
+	enc := StdEncoding.WithPadding('@')
+	for _, p := range pairs {
+		want := strings.ReplaceAll(p.encoded, "=", "@")
+		if got := enc.EncodeToString([]byte(p.decoded)); got != want {
+			t.Errorf("Encode(%q): expected %q, got %q", p.decoded, want, got)
+		}
+		got, err := enc.DecodeString(want)
+		if err != nil || string(got) != p.decoded {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", want, got, err, p.decoded)
+		}
+	}
+}
+
+func TestWithPaddingPanics(t *testing.T) {
+	for _, r := range []rune{'A', '2', '\n', '\r', 0x100, -2, -5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected a panic", r)
+				}
+			}()
+			StdEncoding.WithPadding(r)
+		}()
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		offset int // -1 means no error
+	}{
+		{"", -1},
+		{"!!!!", 0},
+		{"x===", 0},
+		{"AA=A====", 2},
+		{"AAA=AAAA", 3},
+		{"MMMMMMMMM", 8},
+		{"MMMMMMMM", -1},
+		{"A=======", 1},
+		{"AA======", -1},
+		{"AAA=====", 3},
+		{"AAAA====", -1},
+		{"AAAAA===", -1},
+		{"AAAAAA==", 6},
+		{"AAAAAAA=", -1},
+		{"========", 0},
+		{"MZXW6YTB=", 8},
+		{"MY======MY======", 2},
+		{"MZXW6YTBOI!=====", 10},
+	} {
+		dbuf := make([]byte, StdEncoding.DecodedLen(len(tt.in)))
+		_, err := StdEncoding.Decode(dbuf, []byte(tt.in))
+		if tt.offset == -1 {
+			if err != nil {
+				t.Errorf("Decode(%q): unexpected error: %v", tt.in, err)
+			}
+			continue
+		}
+		var e CorruptInputError
+		if !errors.As(err, &e) || int(e) != tt.offset {
+			t.Errorf("Decode(%q): expected CorruptInputError(%d), got %v", tt.in, tt.offset, err)
+		}
+	}
+}
+
+func TestDecodeCorruptPrefix(t *testing.T) {
+	// The bytes before the first invalid block are decoded.
+	in := "MZXW6YTBMZXW6!TB"
+	dbuf := make([]byte, StdEncoding.DecodedLen(len(in)))
+	n, err := StdEncoding.Decode(dbuf, []byte(in))
+	if n != 5 || string(dbuf[:n]) != "fooba" || err != CorruptInputError(13) {
+		t.Fatalf("Decode(%q) = (%q, %v)", in, dbuf[:n], err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	strict := StdEncoding.Strict()
+	for _, tt := range []struct {
+		in string
+		ok bool
+	}{
+		{"MY======", true},
+		{"MZ======", false},
+		{"MZXQ====", true},
+		{"MZXR====", false},
+		{"MZXW6===", true},
+		{"MZXW7===", false},
+		{"MZXW6YQ=", true},
+		{"MZXW6YR=", false},
+		{"MZXW6YTB", true},
+	} {
+		_, err := strict.DecodeString(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("Strict DecodeString(%q): unexpected error %v", tt.in, err)
+		}
+		if _, err := StdEncoding.DecodeString(tt.in); err != nil {
+			t.Errorf("DecodeString(%q): unexpected error %v", tt.in, err)
+		}
+	}
+}
+
+// TestRandom compares the package against encoding/base32.
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, pad := range []rune{StdPadding, NoPadding} {
+		enc := StdEncoding.WithPadding(pad)
+		std := base32.StdEncoding.WithPadding(pad)
+		for n := 0; n < 100; n++ {
+			src := make([]byte, n)
+			rng.Read(src)
+			got := enc.EncodeToString(src)
+			want := std.EncodeToString(src)
+			if got != want {
+				t.Fatalf("Encode(%x): expected %q, got %q", src, want, got)
+			}
+			dec, err := enc.DecodeString(got)
+			if err != nil || !bytes.Equal(dec, src) {
+				t.Fatalf("DecodeString(%q) = (%x, %v)", got, dec, err)
+			}
+			if enc.EncodedLen(n) != std.EncodedLen(n) {
+				t.Fatalf("EncodedLen(%d): expected %d, got %d", n, std.EncodedLen(n), enc.EncodedLen(n))
+			}
+			if m := len(got); enc.DecodedLen(m) != std.DecodedLen(m) {
+				t.Fatalf("DecodedLen(%d): expected %d, got %d", m, std.DecodedLen(m), enc.DecodedLen(m))
+			}
+
+			// Corrupt a random character. Both should fail or
+			// both should succeed.
+			if n > 0 {
+				b := []byte(got)
+				b[rng.Intn(len(b))] = byte(rng.Intn(256))
+				_, err1 := enc.DecodeString(string(b))
+				_, err2 := std.DecodeString(string(b))
+				if (err1 == nil) != (err2 == nil) && !bytes.ContainsAny(b, "\r\n") {
+					t.Fatalf("DecodeString(%q): got %v, encoding/base32 got %v", b, err1, err2)
+				}
+			}
+		}
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	for _, multiplier := range []int{1, 100, 701} {
+		for _, p := range pairs {
+			input := strings.Repeat(p.decoded, multiplier)
+			var buf bytes.Buffer
+			w := NewEncoder(StdEncoding, &buf)
+			r := struct{ io.Reader }{strings.NewReader(input)}
+			if _, err := io.CopyBuffer(w, r, make([]byte, 7)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			want := StdEncoding.EncodeToString([]byte(input))
+			if buf.String() != want {
+				t.Fatalf("encoder(%q*%d): expected %q, got %q", p.decoded, multiplier, want, buf.String())
+			}
+
+			for _, r := range []io.Reader{
+				strings.NewReader(want),
+				iotest.OneByteReader(strings.NewReader(want)),
+				iotest.DataErrReader(strings.NewReader(want)),
+			} {
+				dec := NewDecoder(StdEncoding, r)
+				got, err := io.ReadAll(dec)
+				if err != nil || string(got) != input {
+					t.Fatalf("decoder(%q*%d) = (%q, %v)", p.encoded, multiplier, got, err)
+				}
+			}
+		}
+	}
+}
+
+func TestDecoderErr(t *testing.T) {
+	for _, in := range []string{
+		"MY======MY======",
+		"MZXW6YTB!ZXW6YTB",
+		"MZXW6YTBMZXW6YT",
+	} {
+		for _, r := range []io.Reader{
+			strings.NewReader(in),
+			iotest.OneByteReader(strings.NewReader(in)),
+		} {
+			_, want := StdEncoding.DecodeString(in)
+			_, err := io.ReadAll(NewDecoder(StdEncoding, r))
+			if err != want {
+				t.Errorf("decoder(%q): expected %v, got %v", in, want, err)
+			}
+		}
+	}
+}
+
+func TestEncoderDecoderClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewEncoder(StdEncoding, &buf)
+	w.Write([]byte("foo"))
+	w.Close()
+	e := w.(*encoder)
+	if e.buf != [5]byte{} || e.out != [bufferSize]byte{} {
+		t.Fatal("Close did not wipe the buffers")
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Fatal("expected an error after Close")
+	}
+
+	r := NewDecoder(StdEncoding, strings.NewReader("MZXW6YTBOI======"))
+	var p [1]byte
+	if _, err := r.Read(p[:]); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if r.(*decoder).arr != [bufferSize]byte{} {
+		t.Fatal("Close did not wipe the buffer")
+	}
+	if _, err := r.Read(p[:]); err == nil {
+		t.Fatal("expected an error after Close")
+	}
+}
+
+// RFC 4648 section 10 test vectors for base32hex.
+var hexPairs = []testpair{
+	{"", ""},
+	{"f", "CO======"},
+	{"fo", "CPNG===="},
+	{"foo", "CPNMU==="},
+	{"foob", "CPNMUOG="},
+	{"fooba", "CPNMUOJ1"},
+	{"foobar", "CPNMUOJ1E8======"},
+}
+
+func TestHexEncoding(t *testing.T) {
+	for _, p := range hexPairs {
+		if got := HexEncoding.EncodeToString([]byte(p.decoded)); got != p.encoded {
+			t.Errorf("Encode(%q): expected %q, got %q", p.decoded, p.encoded, got)
+		}
+		got, err := HexEncoding.DecodeString(p.encoded)
+		if err != nil || string(got) != p.decoded {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", p.encoded, got, err, p.decoded)
+		}
+	}
+	if _, err := HexEncoding.DecodeString("WW======"); err != CorruptInputError(0) {
+		t.Errorf("expected %v, got %v", CorruptInputError(0), err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		src := make([]byte, n)
+		rng.Read(src)
+		got := HexEncoding.EncodeToString(src)
+		if want := base32.HexEncoding.EncodeToString(src); got != want {
+			t.Fatalf("Encode(%x): expected %q, got %q", src, want, got)
+		}
+		dec, err := HexEncoding.DecodeString(got)
+		if err != nil || !bytes.Equal(dec, src) {
+			t.Fatalf("DecodeString(%q) = (%x, %v)", got, dec, err)
+		}
+	}
+}
+
+func TestNewEncoding(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, chars := range []string{
+		encodeStd,
+		"0123456789bcdefghjkmnpqrstuvwxyz", // Geohash
+		"23456789CFGHJMPQRVWXcfghjmpqrvwx", // WordSafe
+		"ybndrfg8ejkmcpqxot1uwisza345h769", // z-base-32
+	} {
+		enc := NewEncoding(chars)
+		std := base32.NewEncoding(chars)
+		for n := 0; n < 50; n++ {
+			src := make([]byte, n)
+			rng.Read(src)
+			got := enc.EncodeToString(src)
+			if want := std.EncodeToString(src); got != want {
+				t.Fatalf("%q: Encode(%x): expected %q, got %q", chars, src, want, got)
+			}
+			dec, err := enc.DecodeString(got)
+			if err != nil || !bytes.Equal(dec, src) {
+				t.Fatalf("%q: DecodeString(%q) = (%x, %v)", chars, got, dec, err)
+			}
+		}
+	}
+
+	enc := NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ23456=")
+	if got := enc.EncodeToString([]byte("f")); got != "MY" {
+		t.Errorf("expected %q, got %q", "MY", got)
+	}
+
+	for _, chars := range []string{
+		"",
+		encodeStd[:31],
+		encodeStd + "8",
+		encodeStd[:31] + "\n",
+		encodeStd[:31] + "A",
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewEncoding(%q): expected a panic", chars)
+				}
+			}()
+			NewEncoding(chars)
+		}()
+	}
+}
@@ -0,0 +1,97 @@
+package base32
+
+import (
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const (
+	encodeCrockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// checkCrockford are the check symbols for the values 0
+	// through 36.
+	checkCrockford = encodeCrockford + "*~$=U"
+)
+
+// CrockfordEncoding is Douglas Crockford's base32 encoding,
+// intended for identifiers and keys entered by humans.
+//
+// It encodes with uppercase characters and no padding. Decoding
+// is case insensitive, treats 'O' as '0' and 'I' and 'L' as '1',
+// and ignores hyphens. Offsets in CorruptInputError are counted
+// after removing hyphens.
+var CrockfordEncoding = newCrockford(false)
+
+// CrockfordCheckEncoding is like CrockfordEncoding, but appends
+// Crockford's mod 37 check symbol when encoding and verifies it
+// when decoding.
+//
+// A mismatched check symbol is reported as a CorruptInputError
+// at the offset of the check symbol.
+var CrockfordCheckEncoding = newCrockford(true)
+
+func newCrockford(check bool) *Encoding {
+	enc := newEncoding(encodeCrockford)
+	crockfordAliases(enc.alpha)
+	enc.padChar = NoPadding
+	enc.hyphens = true
+	if check {
+		enc.check = alphabet.New(checkCrockford)
+		crockfordAliases(enc.check)
+	}
+	return enc
+}
+
+// crockfordAliases adds Crockford's decoding aliases to a.
+func crockfordAliases(a *alphabet.Alphabet) {
+	a.Alias('O', 0)
+	a.Alias('I', 1)
+	a.Alias('L', 1)
+	a.FoldCase()
+}
+
+// checkBytes updates the check sum with the bytes in src.
+//
+// checkBytes runs in constant time.
+func checkBytes(sum uint, src []byte) uint {
+	for _, b := range src {
+		// The modulus is a constant, so the compiler replaces
+		// it with a multiplication.
+		sum = (sum<<8 | uint(b)) % 37
+	}
+	return sum
+}
+
+// checkFinish completes a check sum computed with checkBytes
+// over n bytes.
+//
+// The encoded characters represent the bytes shifted left by the
+// padding bits in the final character, so the sum needs to be
+// shifted too.
+func checkFinish(sum uint, n int64) uint {
+	return (sum << uint(2*n%5)) % 37
+}
+
+// checkChars updates the check sum with the characters in src.
+//
+// checkChars runs in constant time.
+func (enc *Encoding) checkChars(sum uint, src []byte) uint {
+	for _, c := range src {
+		v, _ := enc.alpha.Decode(c)
+		sum = (sum<<5 | uint(v)) % 37
+	}
+	return sum
+}
+
+// compactHyphens removes the hyphens from b in place and returns
+// the new length.
+//
+// compactHyphens runs in constant time for the length of b.
+// However, the number of hyphens is revealed by the result.
+func compactHyphens(b []byte) int {
+	j := 0
+	for _, c := range b {
+		b[j] = c
+		j += subtle.ConstantTimeByteEq(c, '-') ^ 1
+	}
+	return j
+}
@@ -0,0 +1,162 @@
+package base32
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCrockford(t *testing.T) {
+	for _, tt := range []struct {
+		decoded, encoded string
+	}{
+		{"", ""},
+		{"f", "CR"},
+		{"foobar", "CSQPYRK1E8"},
+		{"\x00\x00\x00\x00\x00", "00000000"},
+		{"\xff\xff\xff\xff\xff", "ZZZZZZZZ"},
+	} {
+		if got := CrockfordEncoding.EncodeToString([]byte(tt.decoded)); got != tt.encoded {
+			t.Errorf("Encode(%q): expected %q, got %q", tt.decoded, tt.encoded, got)
+		}
+		got, err := CrockfordEncoding.DecodeString(tt.encoded)
+		if err != nil || string(got) != tt.decoded {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", tt.encoded, got, err, tt.decoded)
+		}
+	}
+
+	// Aliases, case folding, and hyphens.
+	for _, s := range []string{
+		"CSQPYRK1E8",
+		"csqpyrk1e8",
+		"CSQPYRKIE8",
+		"CSQPYRKlE8",
+		"CSQP-YRK1-E8",
+		"-CSQPYRK1E8-",
+		"C-S-Q-P-Y-R-K-1-E-8",
+	} {
+		got, err := CrockfordEncoding.DecodeString(s)
+		if err != nil || string(got) != "foobar" {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, nil)", s, got, err, "foobar")
+		}
+	}
+	if got, err := CrockfordEncoding.DecodeString("oo"); err != nil || string(got) != "\x00" {
+		t.Errorf("DecodeString(%q) = (%q, %v)", "oo", got, err)
+	}
+
+	for _, tt := range []struct {
+		in     string
+		offset int
+	}{
+		{"CSQPYRKUE8", 7},
+		{"CS-QPYRKUE8", 7},
+		{"CSQPYRK1E8=", 10},
+		{"C", 1},
+	} {
+		_, err := CrockfordEncoding.DecodeString(tt.in)
+		if err != CorruptInputError(tt.offset) {
+			t.Errorf("DecodeString(%q): expected %v, got %v", tt.in, CorruptInputError(tt.offset), err)
+		}
+	}
+}
+
+// crockfordCheck computes the check symbol of the encoded
+// string s using math/big.
+func crockfordCheck(s string) byte {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		x.Lsh(x, 5)
+		x.Add(x, big.NewInt(int64(strings.IndexByte(encodeCrockford, s[i]))))
+	}
+	return checkCrockford[x.Mod(x, big.NewInt(37)).Int64()]
+}
+
+func TestCrockfordCheck(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		src := make([]byte, n)
+		rng.Read(src)
+		plain := CrockfordEncoding.EncodeToString(src)
+		got := CrockfordCheckEncoding.EncodeToString(src)
+		if want := plain + string(crockfordCheck(plain)); got != want {
+			t.Fatalf("Encode(%x): expected %q, got %q", src, want, got)
+		}
+		if n := CrockfordCheckEncoding.EncodedLen(len(src)); n != len(got) {
+			t.Fatalf("EncodedLen(%d): expected %d, got %d", len(src), len(got), n)
+		}
+		dec, err := CrockfordCheckEncoding.DecodeString(strings.ToLower(got))
+		if err != nil || !bytes.Equal(dec, src) {
+			t.Fatalf("DecodeString(%q) = (%x, %v)", got, dec, err)
+		}
+
+		// Every other check symbol is rejected.
+		for i := 0; i < len(checkCrockford); i++ {
+			c := checkCrockford[i]
+			if c == got[len(got)-1] {
+				continue
+			}
+			bad := got[:len(got)-1] + string(c)
+			if _, err := CrockfordCheckEncoding.DecodeString(bad); err != CorruptInputError(len(got)-1) {
+				t.Fatalf("DecodeString(%q): expected %v, got %v", bad, CorruptInputError(len(got)-1), err)
+			}
+		}
+	}
+
+	// The check symbols are case insensitive too.
+	for b := 0; b < 256; b++ {
+		s := CrockfordCheckEncoding.EncodeToString([]byte{byte(b)})
+		if s[len(s)-1] != 'U' {
+			continue
+		}
+		s = strings.ToLower(s)
+		if got, err := CrockfordCheckEncoding.DecodeString(s); err != nil || got[0] != byte(b) {
+			t.Errorf("DecodeString(%q) = (%x, %v)", s, got, err)
+		}
+	}
+	if _, err := CrockfordCheckEncoding.DecodeString(""); err != CorruptInputError(0) {
+		t.Errorf("expected %v, got %v", CorruptInputError(0), err)
+	}
+}
+
+func TestCrockfordStream(t *testing.T) {
+	for _, enc := range []*Encoding{CrockfordEncoding, CrockfordCheckEncoding} {
+		for _, n := range []int{0, 1, 4, 5, 6, 1000, 4096} {
+			input := make([]byte, n)
+			for i := range input {
+				input[i] = byte(i * 31)
+			}
+			var buf bytes.Buffer
+			w := NewEncoder(enc, &buf)
+			if _, err := io.CopyBuffer(w, struct{ io.Reader }{bytes.NewReader(input)}, make([]byte, 7)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			want := enc.EncodeToString(input)
+			if buf.String() != want {
+				t.Fatalf("%d: encoder: expected %q, got %q", n, want, buf.String())
+			}
+
+			// Insert hyphens every 5 characters.
+			var hyphens strings.Builder
+			for i := 0; i < len(want); i++ {
+				if i%5 == 0 {
+					hyphens.WriteByte('-')
+				}
+				hyphens.WriteByte(want[i])
+			}
+			for _, s := range []string{want, hyphens.String()} {
+				r := iotest.HalfReader(strings.NewReader(s))
+				got, err := io.ReadAll(NewDecoder(enc, r))
+				if err != nil || !bytes.Equal(got, input) {
+					t.Fatalf("%d: decoder(%q) = (%x, %v)", n, s, got, err)
+				}
+			}
+		}
+	}
+}
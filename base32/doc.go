@@ -0,0 +1,11 @@
+// Package base32 implements constant-time base32 encoding as
+// specified by RFC 4648.
+//
+// The API mirrors encoding/base32, but every character is
+// translated without secret-dependent branches or table
+// lookups, so it is suitable for encoding TOTP secrets and
+// other key material.
+//
+// Unlike encoding/base32, decoding does not ignore newline
+// characters.
+package base32
@@ -0,0 +1,248 @@
+package base58
+
+import (
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const (
+	encodeBTC    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	encodeFlickr = "123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	encodeRipple = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+)
+
+// An Alphabet is a base58 alphabet.
+//
+// The package-level functions use BTCAlphabet.
+type Alphabet struct {
+	alpha *alphabet.Alphabet
+}
+
+var (
+	// BTCAlphabet is the alphabet used by Bitcoin.
+	BTCAlphabet = NewAlphabet(encodeBTC)
+	// FlickrAlphabet is the alphabet used by Flickr short URLs.
+	FlickrAlphabet = NewAlphabet(encodeFlickr)
+	// RippleAlphabet is the alphabet used by Ripple.
+	RippleAlphabet = NewAlphabet(encodeRipple)
+)
+
+// NewAlphabet creates an Alphabet from a 58-character string.
+//
+// NewAlphabet panics if s is not 58 bytes long or contains
+// duplicate characters.
+func NewAlphabet(s string) *Alphabet {
+	if len(s) != 58 {
+		panic("base58: alphabet is not 58 bytes long")
+	}
+	var seen [256]bool
+	for i := 0; i < len(s); i++ {
+		if seen[s[i]] {
+			panic("base58: alphabet includes duplicate symbols")
+		}
+		seen[s[i]] = true
+	}
+	return &Alphabet{alpha: alphabet.New(s)}
+}
+
+// CorruptInputError is returned when the input is malformed.
+// Its value is the offset of the first invalid byte.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal base58 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// EncodedLen returns the maximum length of the base58 encoding of
+// n source bytes.
+func EncodedLen(n int) int {
+	// log(256)/log(58) is about 1.37.
+	return n*138/100 + 1
+}
+
+// DecodedLen returns the maximum length of the bytes represented
+// by n base58 characters.
+func DecodedLen(n int) int {
+	// Each leading '1' decodes to a zero byte.
+	return n
+}
+
+// decodedLen returns the number of bytes needed to hold the
+// value of n base58 characters.
+func decodedLen(n int) int {
+	// log(58)/log(256) is about 0.73.
+	return n*733/1000 + 1
+}
+
+// Encode encodes src into at most EncodedLen(len(src)) bytes of
+// dst, returning the actual number of bytes written to dst.
+//
+// Encode runs in constant time for the length of src. However,
+// the result reveals the number of leading zero bytes in src and
+// the magnitude of src.
+func Encode(dst, src []byte) int {
+	return BTCAlphabet.Encode(dst, src)
+}
+
+// Encode is like the package-level Encode, but uses the
+// alphabet a.
+func (a *Alphabet) Encode(dst, src []byte) int {
+	var arr [128]byte
+	digits := arr[:]
+	if size := EncodedLen(len(src)); size > len(arr) {
+		digits = make([]byte, size)
+	} else {
+		digits = arr[:size]
+	}
+	defer subtle.Wipe(digits)
+
+	// Convert src to big-endian base58 digits.
+	for _, b := range src {
+		carry := uint(b)
+		for j := len(digits) - 1; j >= 0; j-- {
+			carry += uint(digits[j]) << 8
+			// The divisor is a constant, so the compiler
+			// replaces the division with a multiplication.
+			digits[j] = byte(carry % 58)
+			carry /= 58
+		}
+	}
+
+	// Each leading zero byte is encoded as a zero digit.
+	zeros := leadingZeros(src)
+	lz := leadingZeros(digits)
+	n := 0
+	for ; n < zeros; n++ {
+		dst[n] = a.alpha.Encode(0)
+	}
+	for _, d := range digits[lz:] {
+		dst[n] = a.alpha.Encode(d)
+		n++
+	}
+	return n
+}
+
+// leadingZeros returns the number of leading zero bytes in b.
+//
+// leadingZeros runs in constant time for the length of b.
+func leadingZeros(b []byte) int {
+	n := 0
+	run := 1
+	for _, c := range b {
+		run &= subtle.ConstantTimeByteEq(c, 0)
+		n += run
+	}
+	return n
+}
+
+// AppendEncode appends the base58 encoding of src to dst and
+// returns the extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	return BTCAlphabet.AppendEncode(dst, src)
+}
+
+// AppendEncode is like the package-level AppendEncode, but uses
+// the alphabet a.
+func (a *Alphabet) AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, EncodedLen(len(src)))
+	n := a.Encode(out, src)
+	return ret[:len(dst)+n]
+}
+
+// EncodeToString returns the base58 encoding of src.
+func EncodeToString(src []byte) string {
+	return BTCAlphabet.EncodeToString(src)
+}
+
+// EncodeToString is like the package-level EncodeToString, but
+// uses the alphabet a.
+func (a *Alphabet) EncodeToString(src []byte) string {
+	return string(a.AppendEncode(nil, src))
+}
+
+// Decode decodes src into at most DecodedLen(len(src)) bytes of
+// dst, returning the actual number of bytes written to dst.
+//
+// If src is malformed, Decode returns zero and
+// a CorruptInputError.
+//
+// Decode runs in constant time for the length of src. However,
+// the result reveals the number of leading zero bytes in the
+// decoded data and its magnitude.
+func Decode(dst, src []byte) (int, error) {
+	return BTCAlphabet.Decode(dst, src)
+}
+
+// Decode is like the package-level Decode, but uses the alphabet
+// a.
+func (a *Alphabet) Decode(dst, src []byte) (int, error) {
+	var arr [128]byte
+	out := arr[:]
+	if size := decodedLen(len(src)); size > len(arr) {
+		out = make([]byte, size)
+	} else {
+		out = arr[:size]
+	}
+	defer subtle.Wipe(out)
+
+	// Convert the base58 digits to big-endian bytes.
+	var bad alphabet.FirstError
+	zeros := 0
+	run := 1
+	for i, c := range src {
+		v, ok := a.alpha.Decode(c)
+		bad.Check(ok^1, i)
+
+		run &= subtle.ConstantTimeByteEq(v, 0) & ok
+		zeros += run
+
+		carry := uint(v)
+		for j := len(out) - 1; j >= 0; j-- {
+			carry += uint(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+	}
+	if bad.Failed() != 0 {
+		return 0, CorruptInputError(bad.Index())
+	}
+
+	// Each leading zero digit is decoded as a zero byte.
+	lz := leadingZeros(out)
+	n := 0
+	for ; n < zeros; n++ {
+		dst[n] = 0
+	}
+	n += copy(dst[n:], out[lz:])
+	return n, nil
+}
+
+// AppendDecode appends the bytes represented by the base58 src
+// to dst and returns the extended buffer.
+//
+// If src is malformed, AppendDecode returns dst and an error.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	return BTCAlphabet.AppendDecode(dst, src)
+}
+
+// AppendDecode is like the package-level AppendDecode, but uses
+// the alphabet a.
+func (a *Alphabet) AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, DecodedLen(len(src)))
+	n, err := a.Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by the base58
+// string s.
+func DecodeString(s string) ([]byte, error) {
+	return BTCAlphabet.DecodeString(s)
+}
+
+// DecodeString is like the package-level DecodeString, but uses
+// the alphabet a.
+func (a *Alphabet) DecodeString(s string) ([]byte, error) {
+	return a.AppendDecode(nil, []byte(s))
+}
@@ -0,0 +1,202 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Test vectors from Bitcoin Core's base58_encode_decode.json.
+var vectors = []struct {
+	hex, encoded string
+}{
+	{"", ""},
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+	{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	{"516b6fcd0f", "ABnLTmg"},
+	{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+	{"572e4794", "3EFU7m"},
+	{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+	{"10c8511e", "Rt5zm"},
+	{"00000000000000000000", "1111111111"},
+	{"000111d38e5fc9071ffcd20b4a763cc9ae4f252bb4e48fd66a835e252ada93ff480d6dd43dc62a641155a5", "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"},
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestEncode(t *testing.T) {
+	for _, v := range vectors {
+		src := mustHex(v.hex)
+		if got := EncodeToString(src); got != v.encoded {
+			t.Errorf("Encode(%s): expected %q, got %q", v.hex, v.encoded, got)
+		}
+		if got := AppendEncode([]byte("x"), src); string(got) != "x"+v.encoded {
+			t.Errorf("AppendEncode(%s): expected %q, got %q", v.hex, "x"+v.encoded, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, v := range vectors {
+		want := mustHex(v.hex)
+		got, err := DecodeString(v.encoded)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("DecodeString(%q) = (%x, %v), want (%s, nil)", v.encoded, got, err, v.hex)
+		}
+		dst := make([]byte, DecodedLen(len(v.encoded)))
+		n, err := Decode(dst, []byte(v.encoded))
+		if err != nil || !bytes.Equal(dst[:n], want) {
+			t.Errorf("Decode(%q) = (%x, %v), want (%s, nil)", v.encoded, dst[:n], err, v.hex)
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		offset int
+	}{
+		{"0", 0},
+		{"O", 0},
+		{"I", 0},
+		{"l", 0},
+		{"2g0", 2},
+		{"1111l", 4},
+		{"3SEo3LWLoPntC ", 13},
+		{"3SEo3L\x00WLoPntC", 6},
+	} {
+		got, err := DecodeString(tt.in)
+		if err != CorruptInputError(tt.offset) || len(got) != 0 {
+			t.Errorf("DecodeString(%q) = (%x, %v), want (nil, %v)",
+				tt.in, got, err, CorruptInputError(tt.offset))
+		}
+	}
+}
+
+// bigEncode is a reference implementation using math/big.
+func bigEncode(src []byte) string {
+	x := new(big.Int).SetBytes(src)
+	var out []byte
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, radix, mod)
+		out = append(out, encodeBTC[mod.Int64()])
+	}
+	for _, b := range src {
+		if b != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		src := make([]byte, n)
+		rng.Read(src)
+		// Exercise leading zeros.
+		for i := 0; i < n && rng.Intn(3) == 0; i++ {
+			src[i] = 0
+		}
+		got := EncodeToString(src)
+		if want := bigEncode(src); got != want {
+			t.Fatalf("Encode(%x): expected %q, got %q", src, want, got)
+		}
+		if len(got) > EncodedLen(n) {
+			t.Fatalf("Encode(%x): %d > EncodedLen(%d) = %d", src, len(got), n, EncodedLen(n))
+		}
+		dec, err := DecodeString(got)
+		if err != nil || !bytes.Equal(dec, src) {
+			t.Fatalf("DecodeString(%q) = (%x, %v)", got, dec, err)
+		}
+	}
+
+	// The largest values for their length.
+	for n := 1; n < 100; n++ {
+		s := strings.Repeat("z", n)
+		dec, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := EncodeToString(dec); got != s {
+			t.Fatalf("expected %q, got %q", s, got)
+		}
+	}
+}
+
+// translate maps s from the Bitcoin alphabet to the alphabet
+// chars.
+func translate(s, chars string) string {
+	b := []byte(s)
+	for i, c := range b {
+		b[i] = chars[strings.IndexByte(encodeBTC, c)]
+	}
+	return string(b)
+}
+
+func TestAlphabet(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		a     *Alphabet
+		chars string
+	}{
+		{"flickr", FlickrAlphabet, encodeFlickr},
+		{"ripple", RippleAlphabet, encodeRipple},
+	} {
+		for _, v := range vectors {
+			want := translate(v.encoded, tc.chars)
+			got := tc.a.EncodeToString(mustHex(v.hex))
+			if got != want {
+				t.Fatalf("%s: EncodeToString(%s) = %q, want %q", tc.name, v.hex, got, want)
+			}
+			dec, err := tc.a.DecodeString(want)
+			if err != nil {
+				t.Fatalf("%s: DecodeString(%q): %v", tc.name, want, err)
+			}
+			if !bytes.Equal(dec, mustHex(v.hex)) {
+				t.Fatalf("%s: DecodeString(%q) = %x, want %s", tc.name, want, dec, v.hex)
+			}
+		}
+	}
+
+	// The Bitcoin encoding is not a valid Flickr encoding.
+	if _, err := FlickrAlphabet.DecodeString("0"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestNewAlphabetPanics(t *testing.T) {
+	for _, s := range []string{
+		"",
+		encodeBTC[1:],
+		encodeBTC + "0",
+		"1" + encodeBTC[1:57] + "1",
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewAlphabet(%q): expected a panic", s)
+				}
+			}()
+			NewAlphabet(s)
+		}()
+	}
+}
@@ -0,0 +1,82 @@
+package base58
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/ericlagergren/subtle"
+)
+
+var (
+	// ErrChecksum is returned by CheckDecode when the checksum
+	// does not match.
+	ErrChecksum = errors.New("base58: checksum mismatch")
+	// ErrInvalidFormat is returned by CheckDecode when the
+	// decoded data is too short to hold a version byte and
+	// a checksum.
+	ErrInvalidFormat = errors.New("base58: invalid format: version and/or checksum bytes missing")
+)
+
+// checksumLen is the length of a Base58Check checksum.
+const checksumLen = 4
+
+// checksum returns the first four bytes of SHA-256(SHA-256(b)).
+func checksum(b []byte) (sum [checksumLen]byte) {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	copy(sum[:], h[:])
+	return
+}
+
+// CheckEncode returns the Base58Check encoding of payload with
+// the version byte version, like Bitcoin addresses and WIF
+// private keys.
+//
+// The encoded data is the version byte, payload, and the first
+// four bytes of the double SHA-256 of the version and payload.
+func CheckEncode(version byte, payload []byte) string {
+	return BTCAlphabet.CheckEncode(version, payload)
+}
+
+// CheckEncode is like the package-level CheckEncode, but uses
+// the alphabet a.
+func (a *Alphabet) CheckEncode(version byte, payload []byte) string {
+	buf := make([]byte, 0, 1+len(payload)+checksumLen)
+	buf = append(buf, version)
+	buf = append(buf, payload...)
+	sum := checksum(buf)
+	buf = append(buf, sum[:]...)
+	s := a.EncodeToString(buf)
+	subtle.Wipe(buf)
+	return s
+}
+
+// CheckDecode decodes the Base58Check string s, returning the
+// version byte and payload.
+//
+// The checksum is verified in constant time. If s is malformed,
+// CheckDecode returns a CorruptInputError, ErrInvalidFormat, or
+// ErrChecksum.
+func CheckDecode(s string) (version byte, payload []byte, err error) {
+	return BTCAlphabet.CheckDecode(s)
+}
+
+// CheckDecode is like the package-level CheckDecode, but uses
+// the alphabet a.
+func (a *Alphabet) CheckDecode(s string) (version byte, payload []byte, err error) {
+	buf, err := a.DecodeString(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(buf) < 1+checksumLen {
+		subtle.Wipe(buf)
+		return 0, nil, ErrInvalidFormat
+	}
+	data := buf[:len(buf)-checksumLen]
+	sum := checksum(data)
+	if subtle.ConstantTimeCompare(sum[:], buf[len(data):]) != 1 {
+		subtle.Wipe(buf)
+		return 0, nil, ErrChecksum
+	}
+	return data[0], data[1:len(data):len(data)], nil
+}
@@ -0,0 +1,71 @@
+package base58
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	for _, tt := range []struct {
+		version byte
+		payload string
+		encoded string
+	}{
+		// Bitcoin P2PKH address.
+		{0x00, "010966776006953d5567439e5e39f86a0d273bee", "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM"},
+		// WIF private key.
+		{0x80, "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d", "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"},
+		{0x00, "", "1Wh4bh"},
+	} {
+		payload := mustHex(tt.payload)
+		if got := CheckEncode(tt.version, payload); got != tt.encoded {
+			t.Errorf("CheckEncode(%#x, %s): expected %q, got %q", tt.version, tt.payload, tt.encoded, got)
+		}
+		version, got, err := CheckDecode(tt.encoded)
+		if err != nil || version != tt.version || !bytes.Equal(got, payload) {
+			t.Errorf("CheckDecode(%q) = (%#x, %x, %v)", tt.encoded, version, got, err)
+		}
+	}
+}
+
+func TestCheckDecodeErr(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		err error
+	}{
+		{"", ErrInvalidFormat},
+		{"1111", ErrInvalidFormat},
+		{"3MNQE1Y", ErrChecksum},
+		{"16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN", ErrChecksum},
+		{"16UwLL9Risc3QfPqBUvKofHmBQ7wMtjv0", CorruptInputError(32)},
+	} {
+		version, payload, err := CheckDecode(tt.in)
+		if err != tt.err || version != 0 || payload != nil {
+			t.Errorf("CheckDecode(%q) = (%#x, %x, %v), want (0, nil, %v)", tt.in, version, payload, err, tt.err)
+		}
+	}
+}
+
+func TestRippleCheck(t *testing.T) {
+	// ACCOUNT_ZERO and ACCOUNT_ONE from the XRP Ledger.
+	for _, tc := range []struct {
+		payload []byte
+		want    string
+	}{
+		{make([]byte, 20), "rrrrrrrrrrrrrrrrrrrrrhoLvTp"},
+		{append(make([]byte, 19), 1), "rrrrrrrrrrrrrrrrrrrrBZbvji"},
+	} {
+		got := RippleAlphabet.CheckEncode(0, tc.payload)
+		if got != tc.want {
+			t.Fatalf("CheckEncode(0, %x) = %q, want %q", tc.payload, got, tc.want)
+		}
+		version, payload, err := RippleAlphabet.CheckDecode(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != 0 || !bytes.Equal(payload, tc.payload) {
+			t.Fatalf("CheckDecode(%q) = (%d, %x), want (0, %x)",
+				got, version, payload, tc.payload)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Package base58 implements constant-time base58 encoding, as
+// used by Bitcoin.
+//
+// Other alphabets, such as those used by Flickr and Ripple, are
+// supported by the same engine. See NewAlphabet.
+//
+// Base58 is not a power-of-two radix, so each output character
+// depends on the entire input. Instead of the usual loop that
+// only touches the significant digits, this package runs
+// schoolbook division over the whole buffer for every input
+// byte, so the work depends only on the length of the input.
+//
+// However, like any base58 implementation, the length of the
+// output reveals the number of leading zero bytes and roughly
+// the magnitude of the input.
+package base58
@@ -0,0 +1,350 @@
+package bech32
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var alpha = func() *alphabet.Alphabet {
+	a := alphabet.New(charset)
+	a.FoldCase()
+	return a
+}()
+
+// An Encoding is a bech32 checksum variant.
+//
+// The variants only differ in the constant that the checksum is
+// compared against.
+type Encoding struct {
+	c uint32 // checksum constant
+}
+
+var (
+	// Bech32 is the original encoding specified by BIP-173,
+	// used by segwit version 0 addresses and Lightning
+	// invoices. It is used by the package-level functions.
+	Bech32 = &Encoding{c: 1}
+	// Bech32m is the encoding specified by BIP-350, used by
+	// segwit version 1 and later addresses, like taproot.
+	Bech32m = &Encoding{c: 0x2bc830a3}
+)
+
+// MaxLength is the maximum length of a bech32 string accepted by
+// Decode.
+const MaxLength = 90
+
+// checksumLen is the number of 5-bit groups in the checksum.
+const checksumLen = 6
+
+var (
+	// ErrChecksum is returned by Decode when the checksum does
+	// not match.
+	ErrChecksum = errors.New("bech32: checksum mismatch")
+	// ErrInvalidFormat is returned by Decode when the string is
+	// missing the separator, the human-readable part, or the
+	// checksum.
+	ErrInvalidFormat = errors.New("bech32: invalid format")
+	// ErrInvalidLength is returned by Decode when the string is
+	// longer than MaxLength.
+	ErrInvalidLength = errors.New("bech32: invalid length")
+	// ErrMixedCase is returned by Decode when the string
+	// contains both lowercase and uppercase characters.
+	ErrMixedCase = errors.New("bech32: mixed case")
+	// ErrInvalidHRP is returned by Encode when the
+	// human-readable part is empty, contains mixed case, or
+	// contains characters outside of [33, 126].
+	ErrInvalidHRP = errors.New("bech32: invalid human-readable part")
+	// ErrInvalidData is returned when a value does not fit in
+	// the number of bits of its group.
+	ErrInvalidData = errors.New("bech32: invalid data")
+	// ErrInvalidPadding is returned by ConvertBits when the
+	// input has an incomplete group or non-zero padding bits.
+	ErrInvalidPadding = errors.New("bech32: invalid padding")
+)
+
+// CorruptInputError is returned when the input contains an
+// invalid character. Its value is the offset of the first
+// invalid byte.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal bech32 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// gen is the BCH generator.
+var gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// polymod updates the checksum chk with the 5-bit value v.
+//
+// polymod runs in constant time.
+func polymod(chk uint32, v byte) uint32 {
+	b := chk >> 25
+	chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+	for i := 0; i < len(gen); i++ {
+		chk ^= -(b >> i & 1) & gen[i]
+	}
+	return chk
+}
+
+// hrpChecksum returns the checksum state after the expanded
+// human-readable part.
+func hrpChecksum(hrp string) uint32 {
+	chk := uint32(1)
+	for i := 0; i < len(hrp); i++ {
+		chk = polymod(chk, hrp[i]>>5)
+	}
+	chk = polymod(chk, 0)
+	for i := 0; i < len(hrp); i++ {
+		chk = polymod(chk, hrp[i]&31)
+	}
+	return chk
+}
+
+// Encode returns the bech32 encoding of the human-readable part
+// hrp and data, which must contain 5-bit values. See
+// ConvertBits.
+//
+// The output is always lowercase. Encode does not limit the
+// length of the output.
+func Encode(hrp string, data []byte) (string, error) {
+	return Bech32.Encode(hrp, data)
+}
+
+// Encode is like the package-level Encode, but uses the
+// encoding enc.
+func (enc *Encoding) Encode(hrp string, data []byte) (string, error) {
+	if !validHRP(hrp) {
+		return "", ErrInvalidHRP
+	}
+	hrp = strings.ToLower(hrp)
+
+	var bad byte
+	for _, v := range data {
+		bad |= v >> 5
+	}
+	if bad != 0 {
+		return "", ErrInvalidData
+	}
+
+	buf := make([]byte, 0, len(hrp)+1+len(data)+checksumLen)
+	buf = append(buf, hrp...)
+	buf = append(buf, '1')
+	chk := hrpChecksum(hrp)
+	for _, v := range data {
+		chk = polymod(chk, v)
+		buf = append(buf, alpha.Encode(v))
+	}
+	for i := 0; i < checksumLen; i++ {
+		chk = polymod(chk, 0)
+	}
+	chk ^= enc.c
+	for i := 0; i < checksumLen; i++ {
+		v := byte(chk>>(5*(checksumLen-1-i))) & 31
+		buf = append(buf, alpha.Encode(v))
+	}
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s, nil
+}
+
+// validHRP reports whether hrp is a valid human-readable part.
+func validHRP(hrp string) bool {
+	if len(hrp) == 0 {
+		return false
+	}
+	var lower, upper bool
+	for i := 0; i < len(hrp); i++ {
+		c := hrp[i]
+		if c < 33 || c > 126 {
+			return false
+		}
+		lower = lower || ('a' <= c && c <= 'z')
+		upper = upper || ('A' <= c && c <= 'Z')
+	}
+	return !(lower && upper)
+}
+
+// Decode decodes the bech32 string s, returning the lowercase
+// human-readable part and the 5-bit data values, without the
+// checksum.
+//
+// The data part is decoded and the checksum is verified in
+// constant time. Decode rejects strings longer than MaxLength;
+// see DecodeNoLimit.
+func Decode(s string) (hrp string, data []byte, err error) {
+	return Bech32.Decode(s)
+}
+
+// Decode is like the package-level Decode, but uses the
+// encoding enc.
+func (enc *Encoding) Decode(s string) (hrp string, data []byte, err error) {
+	if len(s) > MaxLength {
+		return "", nil, ErrInvalidLength
+	}
+	return enc.decode(s)
+}
+
+// DecodeNoLimit is like Decode, but does not limit the length
+// of s. Lightning invoices, for example, are usually longer
+// than MaxLength.
+func DecodeNoLimit(s string) (hrp string, data []byte, err error) {
+	return Bech32.DecodeNoLimit(s)
+}
+
+// DecodeNoLimit is like the package-level DecodeNoLimit, but
+// uses the encoding enc.
+func (enc *Encoding) DecodeNoLimit(s string) (hrp string, data []byte, err error) {
+	return enc.decode(s)
+}
+
+func (enc *Encoding) decode(s string) (string, []byte, error) {
+	// The separator is the last '1', which is never part of the
+	// data since it is not in the alphabet.
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+1+checksumLen > len(s) {
+		return "", nil, ErrInvalidFormat
+	}
+
+	data := make([]byte, len(s)-sep-1)
+
+	// Find the first invalid character and check the case in
+	// constant time.
+	var lower, upper int
+	var bad alphabet.FirstError
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		lower |= subtle.ConstantTimeByteLessOrEq('a', c) &
+			subtle.ConstantTimeByteLessOrEq(c, 'z')
+		upper |= subtle.ConstantTimeByteLessOrEq('A', c) &
+			subtle.ConstantTimeByteLessOrEq(c, 'Z')
+		var ok int
+		switch {
+		case i < sep:
+			ok = subtle.ConstantTimeByteLessOrEq(33, c) &
+				subtle.ConstantTimeByteLessOrEq(c, 126)
+		case i > sep:
+			data[i-sep-1], ok = alpha.Decode(c)
+		default:
+			ok = 1
+		}
+		bad.Check(ok^1, i)
+	}
+	if bad.Failed() != 0 {
+		subtle.Wipe(data)
+		return "", nil, CorruptInputError(bad.Index())
+	}
+	if lower&upper != 0 {
+		subtle.Wipe(data)
+		return "", nil, ErrMixedCase
+	}
+
+	hrp := strings.ToLower(s[:sep])
+	chk := hrpChecksum(hrp)
+	for _, v := range data {
+		chk = polymod(chk, v)
+	}
+	if subtle.ConstantTimeEq(int32(chk), int32(enc.c)) != 1 {
+		subtle.Wipe(data)
+		return "", nil, ErrChecksum
+	}
+	n := len(data) - checksumLen
+	subtle.Wipe(data[n:])
+	return hrp, data[:n:n], nil
+}
+
+// ConvertBits regroups data from fromBits-bit values to
+// toBits-bit values.
+//
+// If pad is true, the final group is padded with zero bits.
+// Otherwise, ConvertBits returns ErrInvalidPadding if the
+// input does not fill a whole number of output groups, except
+// for fewer than fromBits zero bits of padding.
+//
+// ConvertBits runs in constant time for the length of data. It
+// panics if fromBits or toBits is not in [1, 8].
+func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error) {
+	if fromBits < 1 || fromBits > 8 || toBits < 1 || toBits > 8 {
+		panic("bech32: invalid bit group size")
+	}
+	inMask := byte(1<<fromBits - 1)
+	outMask := uint32(1<<toBits - 1)
+
+	n := len(data) * int(fromBits) / int(toBits)
+	if pad && len(data)*int(fromBits)%int(toBits) != 0 {
+		n++
+	}
+	out := make([]byte, 0, n)
+
+	// The number of buffered bits only depends on the length of
+	// data.
+	var acc uint32
+	var bits uint8
+	var bad byte
+	for _, v := range data {
+		bad |= v &^ inMask
+		acc = acc<<fromBits | uint32(v&inMask)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&outMask))
+		}
+		acc &= 1<<bits - 1
+	}
+	if bad != 0 {
+		subtle.Wipe(out)
+		return nil, ErrInvalidData
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&outMask))
+		}
+	} else if bits >= fromBits || acc != 0 {
+		subtle.Wipe(out)
+		return nil, ErrInvalidPadding
+	}
+	return out, nil
+}
+
+// EncodeFromBase256 is like Encode, but first converts data
+// from bytes to 5-bit values.
+func EncodeFromBase256(hrp string, data []byte) (string, error) {
+	return Bech32.EncodeFromBase256(hrp, data)
+}
+
+// EncodeFromBase256 is like the package-level EncodeFromBase256,
+// but uses the encoding enc.
+func (enc *Encoding) EncodeFromBase256(hrp string, data []byte) (string, error) {
+	conv, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	defer subtle.Wipe(conv)
+	return enc.Encode(hrp, conv)
+}
+
+// DecodeToBase256 is like Decode, but converts the decoded data
+// from 5-bit values to bytes.
+func DecodeToBase256(s string) (hrp string, data []byte, err error) {
+	return Bech32.DecodeToBase256(s)
+}
+
+// DecodeToBase256 is like the package-level DecodeToBase256, but
+// uses the encoding enc.
+func (enc *Encoding) DecodeToBase256(s string) (hrp string, data []byte, err error) {
+	hrp, conv, err := enc.Decode(s)
+	if err != nil {
+		return "", nil, err
+	}
+	defer subtle.Wipe(conv)
+	data, err = ConvertBits(conv, 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
@@ -0,0 +1,240 @@
+package bech32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Test vectors from BIP-173.
+var validVectors = []string{
+	"A12UEL5L",
+	"a12uel5l",
+	"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+	"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	"11" + strings.Repeat("q", 82) + "c8247j",
+	"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	"?1ezyfcl",
+}
+
+func TestValid(t *testing.T) {
+	for _, s := range validVectors {
+		hrp, data, err := Decode(s)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", s, err)
+		}
+		got, err := Encode(hrp, data)
+		if err != nil {
+			t.Fatalf("Encode(%q, %v): %v", hrp, data, err)
+		}
+		if want := strings.ToLower(s); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"\x201nwldj5", CorruptInputError(0)},
+		{"\x7f1axkwrx", CorruptInputError(0)},
+		{"\x801eym55h", CorruptInputError(0)},
+		{"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", ErrInvalidLength},
+		{"pzry9x0s0muk", ErrInvalidFormat},
+		{"1pzry9x0s0muk", ErrInvalidFormat},
+		{"x1b4n0q5v", CorruptInputError(2)},
+		{"li1dgmt3", ErrInvalidFormat},
+		{"de1lg7wt\xff", CorruptInputError(8)},
+		{"A1G7SGD8", ErrChecksum},
+		{"10a06t8", ErrInvalidFormat},
+		{"1qzzfhee", ErrInvalidFormat},
+		{"a12UEL5L", ErrMixedCase},
+		{"a12uel5m", ErrChecksum},
+	} {
+		_, _, err := Decode(tc.s)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("Decode(%q): expected %v, got %v", tc.s, tc.err, err)
+		}
+	}
+}
+
+func TestEncodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		hrp  string
+		data []byte
+		err  error
+	}{
+		{"", nil, ErrInvalidHRP},
+		{"a b", nil, ErrInvalidHRP},
+		{"aB", nil, ErrInvalidHRP},
+		{"a", []byte{0, 32}, ErrInvalidData},
+	} {
+		_, err := Encode(tc.hrp, tc.data)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("Encode(%q, %v): expected %v, got %v", tc.hrp, tc.data, tc.err, err)
+		}
+	}
+}
+
+// TestSegwit tests a BIP-173 segwit address.
+func TestSegwit(t *testing.T) {
+	const addr = "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4"
+	prog, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+
+	hrp, data, err := Decode(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrp != "bc" || data[0] != 0 {
+		t.Fatalf("expected (bc, 0), got (%s, %d)", hrp, data[0])
+	}
+	got, err := ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, prog) {
+		t.Fatalf("expected %x, got %x", prog, got)
+	}
+
+	conv, err := ConvertBits(prog, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Encode("bc", append([]byte{0}, conv...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.ToLower(addr); s != want {
+		t.Fatalf("expected %q, got %q", want, s)
+	}
+}
+
+func TestConvertBitsErr(t *testing.T) {
+	for _, tc := range []struct {
+		data     []byte
+		from, to uint8
+		err      error
+	}{
+		{[]byte{32}, 5, 8, ErrInvalidData},
+		{[]byte{1}, 5, 8, ErrInvalidPadding},
+		{[]byte{0, 0, 0}, 5, 8, ErrInvalidPadding},
+		{[]byte{1}, 8, 5, ErrInvalidPadding},
+	} {
+		_, err := ConvertBits(tc.data, tc.from, tc.to, false)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("ConvertBits(%v, %d, %d): expected %v, got %v",
+				tc.data, tc.from, tc.to, tc.err, err)
+		}
+	}
+}
+
+func TestBase256Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		want := make([]byte, n)
+		rng.Read(want)
+		s, err := EncodeFromBase256("lnbc", want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hrp, data, err := DecodeNoLimit(s)
+		if err != nil {
+			t.Fatalf("DecodeNoLimit(%q): %v", s, err)
+		}
+		if hrp != "lnbc" {
+			t.Fatalf("expected lnbc, got %q", hrp)
+		}
+		got, err := ConvertBits(data, 5, 8, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("expected %x, got %x", want, got)
+		}
+		if len(s) > MaxLength {
+			continue
+		}
+		_, got, err = DecodeToBase256(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("expected %x, got %x", want, got)
+		}
+	}
+}
+
+// Test vectors from BIP-350.
+var validBech32mVectors = []string{
+	"A1LQFN3A",
+	"a1lqfn3a",
+	"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+	"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+	"11" + strings.Repeat("l", 83) + "udsr8",
+	"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+	"?1v759aa",
+}
+
+func TestBech32m(t *testing.T) {
+	for _, s := range validBech32mVectors {
+		hrp, data, err := Bech32m.Decode(s)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", s, err)
+		}
+		got, err := Bech32m.Encode(hrp, data)
+		if err != nil {
+			t.Fatalf("Encode(%q, %v): %v", hrp, data, err)
+		}
+		if want := strings.ToLower(s); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		// The checksums are not interchangeable.
+		if _, _, err := Bech32.Decode(s); !errors.Is(err, ErrChecksum) {
+			t.Fatalf("Bech32.Decode(%q): expected %v, got %v", s, ErrChecksum, err)
+		}
+	}
+	for _, s := range validVectors {
+		if _, _, err := Bech32m.Decode(s); !errors.Is(err, ErrChecksum) {
+			t.Fatalf("Bech32m.Decode(%q): expected %v, got %v", s, ErrChecksum, err)
+		}
+	}
+}
+
+// TestTaproot tests a BIP-350 segwit version 1 address.
+func TestTaproot(t *testing.T) {
+	const addr = "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"
+	prog, _ := hex.DecodeString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+
+	conv, err := ConvertBits(prog, 8, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Bech32m.Encode("bc", append([]byte{1}, conv...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != addr {
+		t.Fatalf("expected %q, got %q", addr, s)
+	}
+
+	hrp, data, err := Bech32m.Decode(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrp != "bc" || data[0] != 1 {
+		t.Fatalf("expected (bc, 1), got (%s, %d)", hrp, data[0])
+	}
+	got, err := ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, prog) {
+		t.Fatalf("expected %x, got %x", prog, got)
+	}
+}
@@ -0,0 +1,10 @@
+// Package bech32 implements constant-time bech32 and bech32m
+// encoding as specified by BIP-173 and BIP-350.
+//
+// Every character in the data part is translated without
+// secret-dependent branches or table lookups, and the BCH
+// checksum is computed branchlessly, so the package is suitable
+// for encoding key material, like Lightning and segwit secrets.
+//
+// The human-readable part is not considered secret.
+package bech32
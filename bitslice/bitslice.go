@@ -0,0 +1,135 @@
+package bitslice
+
+import "encoding/binary"
+
+// Transpose8x8 transposes the 8x8 bit matrix x.
+//
+// Row i of the matrix is byte i of x (bits 8*i through 8*i+7)
+// and column j is bit j of each row.
+func Transpose8x8(x uint64) uint64 {
+	t := (x ^ (x >> 7)) & 0x00aa00aa00aa00aa
+	x ^= t ^ (t << 7)
+	t = (x ^ (x >> 14)) & 0x0000cccc0000cccc
+	x ^= t ^ (t << 14)
+	t = (x ^ (x >> 28)) & 0x00000000f0f0f0f0
+	x ^= t ^ (t << 28)
+	return x
+}
+
+// Transpose64x64 transposes the 64x64 bit matrix m in place.
+//
+// Row i of the matrix is m[i] and column j is bit j of each
+// row.
+func Transpose64x64(m *[64]uint64) {
+	mask := uint64(0x00000000ffffffff)
+	for j := 32; j != 0; j >>= 1 {
+		for k := 0; k < 64; k = (k + j + 1) &^ j {
+			t := ((m[k] >> j) ^ m[k+j]) & mask
+			m[k+j] ^= t
+			m[k] ^= t << j
+		}
+		mask ^= mask << (j >> 1)
+	}
+}
+
+// Pack converts the first 64 bytes of src into bitsliced form.
+//
+// Bit i of lanes[k] is set to bit k of src[i].
+//
+// Pack panics if len(src) < 64.
+func Pack(lanes *[8]uint64, src []byte) {
+	_ = src[63] // bounds check hint to compiler
+	*lanes = [8]uint64{}
+	for g := 0; g < 8; g++ {
+		x := Transpose8x8(binary.LittleEndian.Uint64(src[8*g:]))
+		for k := 0; k < 8; k++ {
+			lanes[k] |= uint64(byte(x>>(8*k))) << (8 * g)
+		}
+	}
+}
+
+// Unpack converts bitsliced lanes into 64 bytes of dst.
+//
+// It is the inverse of Pack: bit k of dst[i] is set to bit i of
+// lanes[k].
+//
+// Unpack panics if len(dst) < 64.
+func Unpack(dst []byte, lanes *[8]uint64) {
+	_ = dst[63] // bounds check hint to compiler
+	for g := 0; g < 8; g++ {
+		var x uint64
+		for k := 0; k < 8; k++ {
+			x |= uint64(byte(lanes[k]>>(8*g))) << (8 * k)
+		}
+		binary.LittleEndian.PutUint64(dst[8*g:], Transpose8x8(x))
+	}
+}
+
+// And sets dst[i] = x[i] & y[i] for each lane.
+//
+// It panics if the slices have different lengths.
+func And(dst, x, y []uint64) {
+	checkLen(dst, x, y)
+	for i := range dst {
+		dst[i] = x[i] & y[i]
+	}
+}
+
+// AndNot sets dst[i] = x[i] &^ y[i] for each lane.
+//
+// It panics if the slices have different lengths.
+func AndNot(dst, x, y []uint64) {
+	checkLen(dst, x, y)
+	for i := range dst {
+		dst[i] = x[i] &^ y[i]
+	}
+}
+
+// Or sets dst[i] = x[i] | y[i] for each lane.
+//
+// It panics if the slices have different lengths.
+func Or(dst, x, y []uint64) {
+	checkLen(dst, x, y)
+	for i := range dst {
+		dst[i] = x[i] | y[i]
+	}
+}
+
+// Xor sets dst[i] = x[i] ^ y[i] for each lane.
+//
+// It panics if the slices have different lengths.
+func Xor(dst, x, y []uint64) {
+	checkLen(dst, x, y)
+	for i := range dst {
+		dst[i] = x[i] ^ y[i]
+	}
+}
+
+// Not sets dst[i] = ^x[i] for each lane.
+//
+// It panics if the slices have different lengths.
+func Not(dst, x []uint64) {
+	checkLen(dst, x, x)
+	for i := range dst {
+		dst[i] = ^x[i]
+	}
+}
+
+// Select sets dst[i] = x[i]&m | y[i]&^m for each lane.
+//
+// Each bit of m selects between the corresponding bits of x and
+// y, so in bitsliced form m chooses between whole values.
+//
+// It panics if the slices have different lengths.
+func Select(dst, x, y []uint64, m uint64) {
+	checkLen(dst, x, y)
+	for i := range dst {
+		dst[i] = x[i]&m | y[i]&^m
+	}
+}
+
+func checkLen(dst, x, y []uint64) {
+	if len(dst) != len(x) || len(dst) != len(y) {
+		panic("bitslice: slices have different lengths")
+	}
+}
@@ -0,0 +1,111 @@
+package bitslice
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func bit(x uint64, i int) uint64 {
+	return (x >> i) & 1
+}
+
+func TestTranspose8x8(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		x := rng.Uint64()
+		y := Transpose8x8(x)
+		for i := 0; i < 8; i++ {
+			for j := 0; j < 8; j++ {
+				if bit(x, 8*i+j) != bit(y, 8*j+i) {
+					t.Fatalf("%#016x: (%d, %d) not transposed: %#016x", x, i, j, y)
+				}
+			}
+		}
+		if z := Transpose8x8(y); z != x {
+			t.Fatalf("%#016x: transpose is not an involution: %#016x", x, z)
+		}
+	}
+}
+
+func TestTranspose64x64(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		var x, y [64]uint64
+		for i := range x {
+			x[i] = rng.Uint64()
+		}
+		y = x
+		Transpose64x64(&y)
+		for i := 0; i < 64; i++ {
+			for j := 0; j < 64; j++ {
+				if bit(x[i], j) != bit(y[j], i) {
+					t.Fatalf("(%d, %d) not transposed", i, j)
+				}
+			}
+		}
+		Transpose64x64(&y)
+		if y != x {
+			t.Fatal("transpose is not an involution")
+		}
+	}
+}
+
+func TestPackUnpack(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		src := make([]byte, 64)
+		rng.Read(src)
+
+		var lanes [8]uint64
+		Pack(&lanes, src)
+		for i := 0; i < 64; i++ {
+			for k := 0; k < 8; k++ {
+				if bit(lanes[k], i) != uint64(src[i]>>k)&1 {
+					t.Fatalf("byte %d, bit %d not packed", i, k)
+				}
+			}
+		}
+
+		dst := make([]byte, 64)
+		Unpack(dst, &lanes)
+		if !bytes.Equal(dst, src) {
+			t.Fatalf("expected %x, got %x", src, dst)
+		}
+	}
+}
+
+func TestLogic(t *testing.T) {
+	x := []uint64{0b1100, 0xff}
+	y := []uint64{0b1010, 0x0f}
+	dst := make([]uint64, 2)
+
+	for _, tc := range []struct {
+		name string
+		fn   func()
+		want []uint64
+	}{
+		{"And", func() { And(dst, x, y) }, []uint64{0b1000, 0x0f}},
+		{"AndNot", func() { AndNot(dst, x, y) }, []uint64{0b0100, 0xf0}},
+		{"Or", func() { Or(dst, x, y) }, []uint64{0b1110, 0xff}},
+		{"Xor", func() { Xor(dst, x, y) }, []uint64{0b0110, 0xf0}},
+		{"Not", func() { Not(dst, x) }, []uint64{^uint64(0b1100), ^uint64(0xff)}},
+		{"Select", func() { Select(dst, x, y, 0b0011) }, []uint64{0b1000, 0x0f}},
+	} {
+		tc.fn()
+		for i := range dst {
+			if dst[i] != tc.want[i] {
+				t.Errorf("%s: #%d: expected %#x, got %#x", tc.name, i, tc.want[i], dst[i])
+			}
+		}
+	}
+}
+
+func TestLengthMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	Xor(make([]uint64, 1), make([]uint64, 2), make([]uint64, 1))
+}
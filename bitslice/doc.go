@@ -0,0 +1,10 @@
+// Package bitslice implements utilities for bitsliced
+// computation.
+//
+// Bitslicing rearranges data so that bit k of many independent
+// values is stored in a single machine word (a "lane"). Logic
+// operations on lanes then process every value at once without
+// table lookups or data-dependent branches, which makes the
+// technique a natural fit for constant-time S-boxes, CRCs, and
+// GF(2^n) arithmetic.
+package bitslice
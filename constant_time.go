@@ -1,6 +1,9 @@
 package subtle
 
-import "crypto/subtle"
+import (
+	"crypto/subtle"
+	"math/bits"
+)
 
 // ConstantTimeByteEq returns 1 if x == y and 0 otherwise.
 func ConstantTimeByteEq(x, y uint8) int {
@@ -40,6 +43,33 @@ func ConstantTimeSelect(v, x, y int) int {
 	return subtle.ConstantTimeSelect(v, x, y)
 }
 
+// ConstantTimeAppend copies the first srcLen bytes of src into
+// dst, leaving dst[srcLen:] unchanged, and returns srcLen.
+//
+// The time taken is a function of len(dst) and is independent
+// of srcLen: every byte of dst (and of src) is read and written
+// regardless of how many bytes are copied. This makes it
+// suitable for assembling messages whose field lengths must
+// remain secret.
+//
+// It panics if len(dst) < len(src). Its behavior is undefined if
+// srcLen < 0 or srcLen > len(src).
+func ConstantTimeAppend(dst, src []byte, srcLen int) int {
+	if len(dst) < len(src) {
+		panic("subtle: dst is shorter than src")
+	}
+	for i := range dst {
+		var c byte
+		if i < len(src) {
+			c = src[i]
+		}
+		// mask is 0xff if i < srcLen and 0x00 otherwise.
+		mask := byte((i - srcLen) >> (bits.UintSize - 1))
+		dst[i] = dst[i]&^mask | c&mask
+	}
+	return srcLen
+}
+
 // ConstantTimeBigEndianZero reports, in constant time, whether
 // the big-endian integer x is zero.
 //
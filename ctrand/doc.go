@@ -0,0 +1,7 @@
+// Package ctrand implements random sampling whose timing does
+// not depend on the values it produces.
+//
+// The routines are useful for deriving secret indices and
+// permutations, where the data-dependent retry loops in
+// math/rand and crypto/rand would leak information.
+package ctrand
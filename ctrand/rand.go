@@ -0,0 +1,152 @@
+package ctrand
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// ErrExhausted is returned by Sample when none of the drawn
+// candidates are in range.
+var ErrExhausted = errors.New("ctrand: no candidate was in range")
+
+// Uint64n returns a random integer in [0, n) using randomness
+// read from r.
+//
+// It reads exactly 16 bytes from r and computes
+//
+//	floor(x*n / 2^128)
+//
+// where x is the 128-bit random value. The statistical distance
+// from the uniform distribution is at most n/2^128.
+//
+// Uint64n runs in constant time. It panics if n == 0.
+func Uint64n(r io.Reader, n uint64) (uint64, error) {
+	if n == 0 {
+		panic("ctrand: invalid argument to Uint64n")
+	}
+	var buf [16]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	x0 := binary.LittleEndian.Uint64(buf[0:8])
+	x1 := binary.LittleEndian.Uint64(buf[8:16])
+
+	// x*n = hi1*2^128 + (lo1+hi0)*2^64 + lo0
+	hi1, lo1 := bits.Mul64(x1, n)
+	hi0, _ := bits.Mul64(x0, n)
+	_, carry := bits.Add64(lo1, hi0, 0)
+	return hi1 + carry, nil
+}
+
+// Intn is like Uint64n, but for ints.
+//
+// It panics if n <= 0.
+func Intn(r io.Reader, n int) (int, error) {
+	if n <= 0 {
+		panic("ctrand: invalid argument to Intn")
+	}
+	v, err := Uint64n(r, uint64(n))
+	return int(v), err
+}
+
+// Sample returns a uniformly random integer in [0, n) using
+// rejection sampling with a fixed number of draws.
+//
+// Sample always reads draws candidates from r, each 8 bytes
+// and masked to the bit length of n-1, then selects the first
+// candidate less than n. Unlike Uint64n, the result is exactly
+// uniform. However, Sample returns ErrExhausted if no candidate
+// is in range, which happens with probability less than
+// 2^-draws.
+//
+// Sample runs in constant time for a particular n and draws. It
+// panics if n == 0.
+func Sample(r io.Reader, n uint64, draws int) (uint64, error) {
+	if n == 0 {
+		panic("ctrand: invalid argument to Sample")
+	}
+	mask := uint64(1)<<bits.Len64(n-1) - 1
+
+	var buf [8]byte
+	var v, found uint64
+	for i := 0; i < draws; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, err
+		}
+		c := binary.LittleEndian.Uint64(buf[:]) & mask
+
+		// if found == 0 && c < n {
+		//     v = c
+		//     found = 1
+		// }
+		_, lt := bits.Sub64(c, n, 0)
+		take := lt &^ found
+		v = v&(take-1) | c&-take
+		found |= take
+	}
+	if found == 0 {
+		return 0, ErrExhausted
+	}
+	return v, nil
+}
+
+// Shuffle randomizes the order of n elements using randomness
+// read from r.
+//
+// Shuffle performs a Fisher–Yates shuffle, but instead of
+// swapping elements i and j directly it calls cswap(v, i, k) for
+// every k < i, with v == 1 only when k == j. cswap must swap
+// elements i and k if v == 1 and leave them unchanged if
+// v == 0, in constant time.
+//
+// The sequence of calls to cswap is independent of the
+// resulting permutation. This requires O(n^2) calls to cswap.
+//
+// Shuffle panics if n < 0.
+func Shuffle(r io.Reader, n int, cswap func(v, i, j int)) error {
+	if n < 0 {
+		panic("ctrand: invalid argument to Shuffle")
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := Uint64n(r, uint64(i+1))
+		if err != nil {
+			return err
+		}
+		for k := 0; k < i; k++ {
+			cswap(eq(uint64(k), j), i, k)
+		}
+	}
+	return nil
+}
+
+// Perm returns a random permutation of the integers [0, n)
+// using randomness read from r.
+//
+// Perm runs in constant time for a particular n. It panics if
+// n < 0.
+func Perm(r io.Reader, n int) ([]int, error) {
+	if n < 0 {
+		panic("ctrand: invalid argument to Perm")
+	}
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	err := Shuffle(r, n, func(v, i, j int) {
+		t := (p[i] ^ p[j]) & -v
+		p[i] ^= t
+		p[j] ^= t
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// eq returns 1 if x == y and 0 otherwise.
+func eq(x, y uint64) int {
+	z := x ^ y
+	return int(((z | -z) >> 63) ^ 1)
+}
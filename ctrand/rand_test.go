@@ -0,0 +1,128 @@
+package ctrand
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// constReader returns an infinite stream of b.
+type constReader byte
+
+func (r constReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestUint64n(t *testing.T) {
+	for _, n := range []uint64{1, 2, 3, 7, 10, 1 << 32, 1<<64 - 1} {
+		for i := 0; i < 1000; i++ {
+			v, err := Uint64n(rand.Reader, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v >= n {
+				t.Fatalf("Uint64n(%d) = %d", n, v)
+			}
+		}
+	}
+
+	// All ones is the largest possible random value, so it must
+	// map to n-1.
+	for _, n := range []uint64{1, 2, 3, 1<<64 - 1} {
+		v, err := Uint64n(constReader(0xff), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != n-1 {
+			t.Fatalf("Uint64n(%d) = %d, expected %d", n, v, n-1)
+		}
+	}
+}
+
+func TestUint64nShortRead(t *testing.T) {
+	_, err := Uint64n(bytes.NewReader(make([]byte, 15)), 10)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSample(t *testing.T) {
+	const n = 10
+	var counts [n]int
+	for i := 0; i < 10000; i++ {
+		v, err := Sample(rand.Reader, n, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v >= n {
+			t.Fatalf("Sample(%d) = %d", n, v)
+		}
+		counts[v]++
+	}
+	for i, c := range counts {
+		if c == 0 {
+			t.Fatalf("%d was never sampled", i)
+		}
+	}
+}
+
+func TestSampleExhausted(t *testing.T) {
+	// 0xff..ff masked to 4 bits is 15, which is out of range.
+	_, err := Sample(constReader(0xff), 10, 8)
+	if !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected %v, got %v", ErrExhausted, err)
+	}
+	v, err := Sample(constReader(0x03), 10, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 3 {
+		t.Fatalf("expected 3, got %d", v)
+	}
+}
+
+func TestPerm(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 100} {
+		p, err := Perm(rand.Reader, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := append([]int(nil), p...)
+		sort.Ints(q)
+		for i, v := range q {
+			if v != i {
+				t.Fatalf("Perm(%d) = %v: not a permutation", n, p)
+			}
+		}
+	}
+}
+
+func TestShuffleCalls(t *testing.T) {
+	// The sequence of (i, j) pairs passed to cswap must not
+	// depend on the permutation.
+	trace := func(r constReader) [][2]int {
+		var calls [][2]int
+		err := Shuffle(r, 8, func(v, i, j int) {
+			calls = append(calls, [2]int{i, j})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return calls
+	}
+	x := trace(constReader(0x00))
+	y := trace(constReader(0xff))
+	if len(x) != len(y) {
+		t.Fatalf("call counts differ: %d vs %d", len(x), len(y))
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			t.Fatalf("#%d: %v != %v", i, x[i], y[i])
+		}
+	}
+}
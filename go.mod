@@ -5,4 +5,5 @@ go 1.18
 require (
 	github.com/google/go-cmp v0.5.8
 	golang.org/x/exp v0.0.0-20220428152302-39d4317da171
+	golang.org/x/sys v0.1.0
 )
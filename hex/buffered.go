@@ -0,0 +1,131 @@
+package hex
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// ErrTooLarge is returned when the input would decode to more
+// bytes than allowed.
+var ErrTooLarge = errors.New("hex: input too large")
+
+// BufferedDecoder decodes an entire stream of hexadecimal
+// characters at once.
+//
+// Unlike Decoder, which is only constant time for each chunk
+// read from the underlying io.Reader, BufferedDecoder reads the
+// underlying io.Reader to EOF and then decodes everything in
+// a single constant-time pass. The cost is that the entire
+// stream must fit in memory.
+type BufferedDecoder struct {
+	r   io.Reader
+	max int
+	err error
+	buf []byte // entire stream, decoded in place
+	out []byte // unread decoded bytes
+}
+
+var _ io.ReadCloser = (*BufferedDecoder)(nil)
+
+// NewBufferedDecoder returns a BufferedDecoder that decodes the
+// hexadecimal characters in r, which must decode to at most max
+// bytes.
+//
+// If r holds more than EncodedLen(max) characters, Read returns
+// ErrTooLarge. If r is malformed, Read returns the error from
+// Decode without returning any decoded bytes.
+//
+// Closing the decoder wipes its internal buffer. It does not
+// close r.
+//
+// NewBufferedDecoder panics if max < 0 or if EncodedLen(max)
+// would overflow an int.
+func NewBufferedDecoder(r io.Reader, max int) *BufferedDecoder {
+	if max < 0 || max > math.MaxInt/2 {
+		panic("hex: invalid limit")
+	}
+	return &BufferedDecoder{r: r, max: max}
+}
+
+// Read implements io.Reader.
+//
+// The first call to Read reads the underlying io.Reader to EOF.
+func (d *BufferedDecoder) Read(p []byte) (int, error) {
+	if d.buf == nil && d.err == nil {
+		d.err = d.decode()
+	}
+	if len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// decode reads and decodes the entire underlying io.Reader.
+func (d *BufferedDecoder) decode() error {
+	limit := EncodedLen(d.max)
+	size := 512
+	if size > limit+1 {
+		size = limit + 1
+	}
+	buf := make([]byte, 0, size)
+	for {
+		if len(buf) == cap(buf) {
+			if len(buf) > limit {
+				subtle.Wipe(buf)
+				return ErrTooLarge
+			}
+			// Grow by hand so that the old buffer can be wiped.
+			size = 2 * cap(buf)
+			if size > limit+1 {
+				size = limit + 1
+			}
+			tmp := make([]byte, len(buf), size)
+			copy(tmp, buf)
+			subtle.Wipe(buf)
+			buf = tmp
+		}
+		n, err := d.r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			subtle.Wipe(buf)
+			return err
+		}
+	}
+	if len(buf) > limit {
+		subtle.Wipe(buf)
+		return ErrTooLarge
+	}
+
+	n, err := DecodeInPlace(buf)
+	if err != nil {
+		subtle.Wipe(buf)
+		return err
+	}
+	d.buf = buf
+	d.out = buf[:n]
+	return nil
+}
+
+// Close wipes the decoder's internal buffer and discards any
+// unread decoded bytes. Subsequent calls to Read return an
+// error.
+//
+// Close does not close the underlying io.Reader.
+func (d *BufferedDecoder) Close() error {
+	subtle.Wipe(d.buf)
+	d.buf = nil
+	d.out = nil
+	d.err = errDecoderClosed
+	return nil
+}
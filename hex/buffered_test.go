@@ -0,0 +1,111 @@
+package hex
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBufferedDecoder(t *testing.T) {
+	for _, multiplier := range []int{1, 128, 1000} {
+		for _, test := range encDecTests {
+			input := strings.Repeat(test.enc, multiplier)
+			output := bytes.Repeat(test.dec, multiplier)
+
+			dec := NewBufferedDecoder(iotest.HalfReader(strings.NewReader(input)), len(output))
+			got, err := io.ReadAll(iotest.OneByteReader(dec))
+			if err != nil || !bytes.Equal(got, output) {
+				t.Errorf("ReadAll(%q*%d) = (%x, %v), want (%x, nil)",
+					test.enc, multiplier, got, err, output)
+			}
+		}
+	}
+}
+
+func TestBufferedDecoderErr(t *testing.T) {
+	for _, tt := range errTests {
+		dec := NewBufferedDecoder(strings.NewReader(tt.in), 100)
+		got, err := io.ReadAll(dec)
+		if tt.err == nil {
+			if err != nil || string(got) != tt.out {
+				t.Errorf("ReadAll(%q) = (%q, %v), want (%q, nil)", tt.in, got, err, tt.out)
+			}
+			continue
+		}
+		// Nothing is returned for malformed input.
+		if len(got) != 0 || err != tt.err {
+			t.Errorf("ReadAll(%q) = (%q, %v), want (\"\", %v)", tt.in, got, err, tt.err)
+		}
+	}
+
+	errRead := errors.New("read error")
+	dec := NewBufferedDecoder(iotest.ErrReader(errRead), 100)
+	if _, err := io.ReadAll(dec); err != errRead {
+		t.Fatalf("expected %v, got %v", errRead, err)
+	}
+}
+
+func TestBufferedDecoderMax(t *testing.T) {
+	const max = 1000
+	for _, n := range []int{0, 1, max - 1, max, max + 1, 4 * max} {
+		input := strings.Repeat("ab", n)
+		dec := NewBufferedDecoder(strings.NewReader(input), max)
+		got, err := io.ReadAll(dec)
+		if n > max {
+			if err != ErrTooLarge || len(got) != 0 {
+				t.Errorf("%d: expected %v, got (%d, %v)", n, ErrTooLarge, len(got), err)
+			}
+			continue
+		}
+		if err != nil || len(got) != n {
+			t.Errorf("%d: got (%d, %v)", n, len(got), err)
+		}
+	}
+
+	dec := NewBufferedDecoder(strings.NewReader("ab"), 0)
+	if _, err := io.ReadAll(dec); err != ErrTooLarge {
+		t.Fatalf("expected %v, got %v", ErrTooLarge, err)
+	}
+}
+
+func TestBufferedDecoderInvalidMax(t *testing.T) {
+	for _, max := range []int{-1, math.MinInt, math.MaxInt/2 + 1, math.MaxInt} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%d: expected a panic", max)
+				}
+			}()
+			NewBufferedDecoder(strings.NewReader("ab"), max)
+		}()
+	}
+
+	// The largest limit is fine.
+	dec := NewBufferedDecoder(strings.NewReader("ab"), math.MaxInt/2)
+	got, err := io.ReadAll(dec)
+	if err != nil || !bytes.Equal(got, []byte{0xab}) {
+		t.Fatalf("ReadAll = (%x, %v)", got, err)
+	}
+}
+
+func TestBufferedDecoderClose(t *testing.T) {
+	dec := NewBufferedDecoder(strings.NewReader("deadbeef"), 4)
+	var p [1]byte
+	if n, err := dec.Read(p[:]); n != 1 || err != nil || p[0] != 0xde {
+		t.Fatalf("Read = (%d, %v, %#x)", n, err, p[0])
+	}
+	buf := dec.buf
+	if err := dec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Fatal("Close did not wipe the buffer")
+	}
+	if _, err := dec.Read(p[:]); err == nil || err == io.EOF {
+		t.Fatalf("expected an error after Close, got %v", err)
+	}
+}
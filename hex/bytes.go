@@ -0,0 +1,48 @@
+package hex
+
+import (
+	"encoding"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// Bytes is a byte slice that is marshaled as lowercase
+// hexadecimal text.
+//
+// It allows structs holding keys to be used with encoding/json
+// and other packages that use encoding.TextMarshaler.
+type Bytes []byte
+
+var (
+	_ encoding.TextMarshaler   = Bytes(nil)
+	_ encoding.TextUnmarshaler = (*Bytes)(nil)
+)
+
+// MarshalText implements encoding.TextMarshaler.
+//
+// MarshalText runs in constant time for the length of b.
+func (b Bytes) MarshalText() ([]byte, error) {
+	return AppendEncode(nil, b), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+//
+// The previous contents of b are wiped. If text is malformed,
+// UnmarshalText sets b to nil and returns ErrCorrupt, which does
+// not reveal where or why decoding failed.
+//
+// UnmarshalText runs in constant time for the length of text.
+func (b *Bytes) UnmarshalText(text []byte) error {
+	subtle.Wipe(*b)
+	buf := (*b)[:0]
+	if cap(buf) < DecodedLen(len(text)) {
+		buf = make([]byte, DecodedLen(len(text)))
+	}
+	buf = buf[:DecodedLen(len(text))]
+	if _, err := DecodeStrict(buf, text); err != nil {
+		*b = nil
+		return err
+	}
+	*b = buf
+	return nil
+}
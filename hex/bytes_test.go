@@ -0,0 +1,53 @@
+package hex
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBytesJSON(t *testing.T) {
+	type config struct {
+		Key Bytes `json:"key"`
+	}
+	for i, test := range encDecTests {
+		data, err := json.Marshal(config{Key: test.dec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"key":"` + test.enc + `"}`; string(data) != want {
+			t.Errorf("#%d: expected %s, got %s", i, want, data)
+		}
+		var c config
+		if err := json.Unmarshal([]byte(`{"key":"`+test.enc+`"}`), &c); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(c.Key, test.dec) {
+			t.Errorf("#%d: got: %#v want: #%v", i, c.Key, test.dec)
+		}
+	}
+}
+
+func TestBytesUnmarshalText(t *testing.T) {
+	b := Bytes{1, 2, 3, 4}
+	old := b
+	if err := b.UnmarshalText([]byte("abcd")); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, []byte{0xab, 0xcd}) {
+		t.Fatalf("got %x", b)
+	}
+	if !bytes.Equal(old[2:], []byte{0, 0}) {
+		t.Fatalf("old contents not wiped: %x", old)
+	}
+
+	for _, tt := range errTests {
+		if tt.err == nil {
+			continue
+		}
+		b := Bytes{1, 2, 3, 4}
+		if err := b.UnmarshalText([]byte(tt.in)); err != ErrCorrupt || b != nil {
+			t.Errorf("UnmarshalText(%q) = (%x, %v), want (nil, %v)", tt.in, b, err, ErrCorrupt)
+		}
+	}
+}
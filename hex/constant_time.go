@@ -18,7 +18,12 @@
 
 package hex
 
-import "crypto/subtle"
+import (
+	"encoding/binary"
+	"runtime"
+
+	"github.com/ericlagergren/subtle"
+)
 
 // Encode encodes src into EncodedLen(len(src)) bytes of dst.
 // As a convenience, it returns the number of bytes written to
@@ -26,16 +31,110 @@ import "crypto/subtle"
 //
 // Encode runs in constant time for the length of src.
 func Encode(dst, src []byte) int {
+	return encode(dst, src, lowerAlpha)
+}
+
+// EncodeUpper is like Encode, but uses uppercase hexadecimal
+// characters.
+//
+// EncodeUpper runs in constant time for the length of src.
+func EncodeUpper(dst, src []byte) int {
+	return encode(dst, src, upperAlpha)
+}
+
+const (
+	// lowerAlpha encodes 10 ... 15 as 'a' ... 'f'.
+	lowerAlpha = 'a' - 10
+	// upperAlpha encodes 10 ... 15 as 'A' ... 'F'.
+	upperAlpha = 'A' - 10
+)
+
+// encode implements Encode and EncodeUpper.
+//
+// alpha is either lowerAlpha or upperAlpha.
+//
+// encode reads each block of src before writing to dst, so src
+// may alias the back half of dst.
+func encode(dst, src []byte, alpha uint) int {
+	// If c < 10, subtracting 10 produces the two's complement,
+	// and shifting by 8 leaves all ones in bits [7:0]. ANDing
+	// that with mask and adding it to alpha+c produces '0'+c.
+	//
+	// Otherwise, the subtraction and shift produce zero, which
+	// leaves alpha+c.
+	n := len(src) * 2
+
+	// Encode 8 bytes at a time.
+	for len(src) >= 8 {
+		x := binary.BigEndian.Uint64(src)
+		binary.BigEndian.PutUint64(dst[0:], encodeSWAR(uint32(x>>32), alpha))
+		binary.BigEndian.PutUint64(dst[8:], encodeSWAR(uint32(x), alpha))
+		src = src[8:]
+		dst = dst[16:]
+	}
+
+	mask := '0' - alpha
 	j := 0
 	for _, v := range src {
 		b := uint(v >> 4)
 		c := uint(v & 0x0f)
 
+		dst[j+1] = byte(alpha + c + (((c - 10) >> 8) & mask))
+		dst[j] = byte(alpha + b + (((b - 10) >> 8) & mask))
+		j += 2
+	}
+	return n
+}
+
+// encodeSWAR encodes the four bytes of x into eight hexadecimal
+// characters, packed big-endian.
+//
+// alpha is either lowerAlpha or upperAlpha.
+func encodeSWAR(x uint32, alpha uint) uint64 {
+	// Spread each byte of x into its own 16-bit lane:
+	//
+	//    b0 b1 b2 b3 -> 00 b0 00 b1 00 b2 00 b3
+	//
+	v := uint64(x)
+	v = (v | v<<16) & 0x0000ffff0000ffff
+	v = (v | v<<8) & 0x00ff00ff00ff00ff
+
+	// Split each byte into nibbles, high nibble first:
+	//
+	//    00 b0 -> h0 l0
+	//
+	const lo = 0x000f000f000f000f
+	v = (v>>4)&lo<<8 | v&lo
+
+	// Each byte of v is now a nibble c. Adding 0x76 sets bit 7
+	// iff c >= 10 and cannot carry into the next byte since
+	// c <= 15.
+	ge10 := ((v + 0x7676767676767676) >> 7) & 0x0101010101010101
+
+	// '0'+c for c < 10 and alpha+c for c >= 10.
+	return v + 0x3030303030303030 + ge10*uint64(alpha-'0')
+}
+
+// EncodeReversed is like Encode, but encodes the bytes of src in
+// reverse order.
+//
+// This produces the byte-reversed ("little-endian") hexadecimal
+// form used by Bitcoin transaction IDs and some hardware
+// registers.
+//
+// EncodeReversed runs in constant time for the length of src.
+func EncodeReversed(dst, src []byte) int {
+	j := 0
+	for i := len(src) - 1; i >= 0; i-- {
+		v := src[i]
+		b := uint(v >> 4)
+		c := uint(v & 0x0f)
+
 		const (
 			mask = ^uint(38)
 		)
-		dst[j+1] = byte(87 + c + (((c - 10) >> 8) & mask))
-		dst[j] = byte(87 + b + (((b - 10) >> 8) & mask))
+		dst[j+1] = byte(lowerAlpha + c + (((c - 10) >> 8) & mask))
+		dst[j] = byte(lowerAlpha + b + (((b - 10) >> 8) & mask))
 		j += 2
 	}
 	return len(src) * 2
@@ -48,8 +147,84 @@ func Encode(dst, src []byte) int {
 // and that src has even length. If the input is malformed,
 // Decode returns the number of bytes decoded before the error.
 //
+// src may alias dst if both begin at the same address. See
+// DecodeInPlace.
+//
 // Decode runs in constant time for the length of src.
 func Decode(dst, src []byte) (int, error) {
+	// The remainder is always decoded, even if the accelerated
+	// blocks contain an error, so that the time taken does not
+	// depend on the input.
+	n, bad, accelErr := decodeAccel(dst, src)
+	m, err := decode(dst[n/2:], src[n:], false)
+	if accelErr != nil {
+		return bad, accelErr
+	}
+	return n/2 + m, err
+}
+
+// DecodeStrict is like Decode, but does not reveal where or why
+// decoding failed.
+//
+// If src is malformed for any reason, DecodeStrict returns
+// (0, ErrCorrupt) and zeroes dst[:DecodedLen(len(src))] so that
+// the partially decoded output does not reveal the position of
+// the error either.
+//
+// DecodeStrict runs in constant time for the length of src.
+func DecodeStrict(dst, src []byte) (int, error) {
+	n, err := Decode(dst, src)
+	return strictResult(dst[:DecodedLen(len(src))], n, err)
+}
+
+// strictResult zeroes out and returns (0, ErrCorrupt) if err is
+// not nil. Otherwise, it returns (n, nil).
+func strictResult(out []byte, n int, err error) (int, error) {
+	if wipeOnError(out, err) != nil {
+		return 0, ErrCorrupt
+	}
+	return n, nil
+}
+
+// wipeOnError zeroes out if err is not nil and returns err.
+//
+// out is processed either way so that valid and invalid input
+// take the same time.
+func wipeOnError(out []byte, err error) error {
+	ok := 1
+	if err != nil {
+		ok = 0
+	}
+	mask := byte(-ok)
+	for i := range out {
+		out[i] &= mask
+	}
+	runtime.KeepAlive(out)
+	return err
+}
+
+// DecodeReversed is like Decode, but writes the decoded bytes to
+// dst in reverse order.
+//
+// It decodes the byte-reversed ("little-endian") hexadecimal
+// form produced by EncodeReversed. The decoded bytes occupy
+// dst[:DecodedLen(len(src))] in reverse order, so if the input
+// is malformed the n bytes decoded before the error are found
+// at the end of that range. dst must not overlap src.
+//
+// DecodeReversed runs in constant time for the length of src.
+func DecodeReversed(dst, src []byte) (int, error) {
+	return decode(dst, src, true)
+}
+
+// decode implements Decode and DecodeReversed.
+func decode(dst, src []byte, reverse bool) (int, error) {
+	// n is the number of bytes written to dst for well-formed
+	// input.
+	n := len(src) / 2
+	if reverse && n > 0 {
+		_ = dst[n-1] // bounds check hint to compiler
+	}
 	// failed is set to 1 if the input is malformed, 0 otherwise.
 	var failed int
 	// badIdx is the number of bytes written to dst when
@@ -182,7 +357,11 @@ func Decode(dst, src []byte) (int, error) {
 		if j%2 == 0 {
 			acc = val * 16
 		} else {
-			dst[i] = acc | val
+			k := i
+			if reverse {
+				k = n - 1 - i
+			}
+			dst[k] = acc | val
 			i++
 		}
 	}
@@ -198,12 +377,78 @@ func Decode(dst, src []byte) (int, error) {
 	return i, nil
 }
 
+// Valid reports whether src is a valid hexadecimal encoding:
+// it has even length and contains only hexadecimal characters.
+//
+// Valid runs in constant time for the length of src.
+func Valid(src []byte) bool {
+	ok := 1
+	for _, c := range src {
+		ok &= isHexChar(c)
+	}
+	return ok == 1 && len(src)%2 == 0
+}
+
+// ValidString is like Valid, but for strings.
+//
+// ValidString runs in constant time for the length of s.
+func ValidString(s string) bool {
+	return Valid(unsafeBytes(s))
+}
+
 // validHexChar reports, in constant time, whether c is a valid
 // hexadecimal character.
 func validHexChar(c byte) bool {
+	return isHexChar(c) == 1
+}
+
+// isHexChar returns 1 if c is a valid hexadecimal character and
+// 0 otherwise.
+//
+// isHexChar runs in constant time. See Decode for an
+// explanation.
+func isHexChar(c byte) int {
+	_, ok := fromHexChar(c)
+	return ok
+}
+
+// fromHexChar returns the value of the hexadecimal character c
+// and 1, or zero and 0 if c is not a hexadecimal character.
+//
+// fromHexChar runs in constant time. See Decode for an
+// explanation.
+func fromHexChar(c byte) (byte, int) {
 	num := uint(c) ^ '0'
 	num0 := (num - 10) >> 8
 	alpha := (uint(c) & ^uint(32)) - 55
 	alpha0 := ((alpha - 10) ^ (alpha - 16)) >> 8
-	return subtle.ConstantTimeByteEq(byte(num0|alpha0), 0) == 0
+	val := byte(num0&num | alpha0&alpha)
+	return val, subtle.ConstantTimeByteEq(byte(num0|alpha0), 0) ^ 1
+}
+
+// EqualEncoded returns 1 if encoded is the hexadecimal encoding
+// of raw and 0 otherwise. Both lowercase and uppercase
+// characters are accepted.
+//
+// Unlike decoding encoded and comparing the result with raw,
+// EqualEncoded does not allocate, so it is suitable for
+// verifying a presented hexadecimal MAC or token against stored
+// raw bytes.
+//
+// EqualEncoded returns 0 immediately if len(encoded) !=
+// EncodedLen(len(raw)). Otherwise, it runs in constant time for
+// the length of raw.
+func EqualEncoded(encoded, raw []byte) int {
+	if len(encoded) != EncodedLen(len(raw)) {
+		return 0
+	}
+	var v byte
+	ok := 1
+	for i, b := range raw {
+		hi, ok0 := fromHexChar(encoded[2*i])
+		lo, ok1 := fromHexChar(encoded[2*i+1])
+		v |= (hi<<4 | lo) ^ b
+		ok &= ok0 & ok1
+	}
+	return subtle.ConstantTimeByteEq(v, 0) & ok
 }
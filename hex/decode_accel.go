@@ -0,0 +1,37 @@
+//go:build (amd64 || arm64) && !purego
+
+package hex
+
+import "github.com/ericlagergren/subtle"
+
+// accelResult converts the result of an accelerated decoder
+// that consumed n 16-byte blocks into the results of
+// decodeAccel.
+//
+// If failed is 1, block is the index of the first block with an
+// invalid character, valid has bit i set if character i of the
+// block is valid, and lo and hi are the characters of the block
+// in little-endian order.
+//
+// accelResult runs in constant time.
+func accelResult(n, failed, block int, valid uint16, lo, hi uint64) (int, int, error) {
+	// Find the first invalid character of the block in
+	// constant time. If every block is valid, valid has no
+	// clear bits and nothing is selected.
+	var found, pos, c int
+	for i := 0; i < 16; i++ {
+		x := lo >> (8 * i)
+		if i >= 8 {
+			x = hi >> (8 * (i - 8))
+		}
+		bad := int(valid>>i&1) ^ 1
+		first := bad &^ found
+		pos = subtle.ConstantTimeSelect(first, i, pos)
+		c = subtle.ConstantTimeSelect(first, int(byte(x)), c)
+		found |= first
+	}
+	if failed != 0 {
+		return 16 * n, 8*block + pos/2, InvalidByteError(c)
+	}
+	return 16 * n, 0, nil
+}
@@ -0,0 +1,26 @@
+//go:build (amd64 || arm64) && !purego
+
+package hex
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ericlagergren/subtle/internal/cpu"
+)
+
+// TestDecodeAccelRegistered tests that each architecture with an
+// accelerated decoder registers it, so that TestDecodeImpls
+// compares it against the generic code.
+func TestDecodeAccelRegistered(t *testing.T) {
+	want := map[string]string{
+		"amd64": "ssse3",
+		"arm64": "neon",
+	}[runtime.GOARCH]
+	for _, impl := range cpu.Impls("hex") {
+		if impl.Name == want {
+			return
+		}
+	}
+	t.Fatalf("%s implementation not registered", want)
+}
@@ -0,0 +1,37 @@
+//go:build amd64 && !purego
+
+package hex
+
+import "github.com/ericlagergren/subtle/internal/cpu"
+
+var ssse3 = cpu.Register("hex", "ssse3", cpu.X86.HasSSSE3)
+
+// decodeBlocksSSSE3 decodes the n 16-byte blocks at src into
+// 8*n bytes at dst, whether or not they are valid.
+//
+// If any block contains an invalid character, failed is 1 and
+// block, mask, lo, and hi are the index, the validity mask
+// (one bit per character), and the characters of the first
+// such block.
+//
+//go:noescape
+func decodeBlocksSSSE3(dst, src *byte, n int) (failed, block, mask int, lo, hi uint64)
+
+// decodeAccel decodes as many 16-byte blocks of src as possible
+// using SIMD instructions and returns the number of bytes of
+// src consumed.
+//
+// If the blocks contain an invalid character, decodeAccel also
+// returns the number of bytes decoded before it and an
+// InvalidByteError.
+//
+// decodeAccel runs in constant time for the length of src.
+func decodeAccel(dst, src []byte) (int, int, error) {
+	n := len(src) / 16
+	if n == 0 || !ssse3.Enabled() {
+		return 0, 0, nil
+	}
+	_ = dst[8*n-1] // bounds check
+	failed, block, mask, lo, hi := decodeBlocksSSSE3(&dst[0], &src[0], n)
+	return accelResult(n, failed, block, uint16(mask), lo, hi)
+}
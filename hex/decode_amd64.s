@@ -0,0 +1,129 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// Each block of 16 characters is decoded as follows:
+//
+//    d  = c - '0'
+//    md = d <= 9            (unsigned)
+//    l  = (c | 0x20) - 'a'
+//    ml = l <= 5            (unsigned)
+//    v  = d&md | (l+10)&ml
+//
+// The character is valid iff md|ml is set. Pairs of nibbles
+// are then combined with PMADDUBSW (hi*16 + lo) and packed into
+// bytes with PACKUSWB.
+
+DATA ·zeroChar<>+0x00(SB)/8, $0x3030303030303030
+DATA ·zeroChar<>+0x08(SB)/8, $0x3030303030303030
+GLOBL ·zeroChar<>(SB), RODATA|NOPTR, $16
+
+DATA ·nine<>+0x00(SB)/8, $0x0909090909090909
+DATA ·nine<>+0x08(SB)/8, $0x0909090909090909
+GLOBL ·nine<>(SB), RODATA|NOPTR, $16
+
+DATA ·lowerBit<>+0x00(SB)/8, $0x2020202020202020
+DATA ·lowerBit<>+0x08(SB)/8, $0x2020202020202020
+GLOBL ·lowerBit<>(SB), RODATA|NOPTR, $16
+
+DATA ·aChar<>+0x00(SB)/8, $0x6161616161616161
+DATA ·aChar<>+0x08(SB)/8, $0x6161616161616161
+GLOBL ·aChar<>(SB), RODATA|NOPTR, $16
+
+DATA ·five<>+0x00(SB)/8, $0x0505050505050505
+DATA ·five<>+0x08(SB)/8, $0x0505050505050505
+GLOBL ·five<>(SB), RODATA|NOPTR, $16
+
+DATA ·ten<>+0x00(SB)/8, $0x0a0a0a0a0a0a0a0a
+DATA ·ten<>+0x08(SB)/8, $0x0a0a0a0a0a0a0a0a
+GLOBL ·ten<>(SB), RODATA|NOPTR, $16
+
+// Multiply the high nibble by 16 and the low nibble by 1.
+DATA ·weights<>+0x00(SB)/8, $0x0110011001100110
+DATA ·weights<>+0x08(SB)/8, $0x0110011001100110
+GLOBL ·weights<>(SB), RODATA|NOPTR, $16
+
+#define LOAD_CONSTANTS \
+	MOVOU ·zeroChar<>(SB), X8 \
+	MOVOU ·nine<>(SB), X9     \
+	MOVOU ·lowerBit<>(SB), X10 \
+	MOVOU ·aChar<>(SB), X11   \
+	MOVOU ·five<>(SB), X12
+
+// CLASSIFY computes d and md into X1 and X2 and l and ml into
+// X3 and X4 for the characters in X0.
+#define CLASSIFY \
+	MOVOU   X0, X1  \
+	PSUBB   X8, X1  \
+	MOVOU   X1, X2  \
+	PMINUB  X9, X2  \
+	PCMPEQB X1, X2  \
+	MOVOU   X0, X3  \
+	POR     X10, X3 \
+	PSUBB   X11, X3 \
+	MOVOU   X3, X4  \
+	PMINUB  X12, X4 \
+	PCMPEQB X3, X4
+
+// func decodeBlocksSSSE3(dst, src *byte, n int) (failed, block, mask int, lo, hi uint64)
+//
+// Every block is decoded and written to dst, valid or not. The
+// first invalid block is recorded with CMOV, so the loop does not
+// branch on the input.
+TEXT ·decodeBlocksSSSE3(SB), NOSPLIT, $0-64
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+	LOAD_CONSTANTS
+	MOVOU ·ten<>(SB), X13
+	MOVOU ·weights<>(SB), X14
+
+	XORQ BX, BX     // index of the current block
+	XORQ R12, R12   // 1 once an invalid block has been seen
+	XORQ R13, R13   // index of the first invalid block
+	MOVQ $0xffff, R14 // validity mask of the first invalid block
+	XORQ R10, R10   // characters of the first invalid block
+	XORQ R11, R11
+
+decodeLoop:
+	MOVOU (SI), X0
+	MOVQ  (SI), R8
+	MOVQ  8(SI), R9
+	CLASSIFY
+
+	// AX = 1 iff this is the first invalid block.
+	MOVOU    X2, X5
+	POR      X4, X5
+	PMOVMSKB X5, DX
+	XORL     AX, AX
+	CMPL     DX, $0xffff
+	SETNE    AX
+	XORQ     $1, R12
+	ANDQ     R12, AX
+	XORQ     $1, R12
+	ORQ      AX, R12
+	TESTQ    AX, AX
+	CMOVQNE  BX, R13
+	CMOVQNE  DX, R14
+	CMOVQNE  R8, R10
+	CMOVQNE  R9, R11
+
+	PAND      X2, X1
+	PADDB     X13, X3
+	PAND      X4, X3
+	POR       X3, X1
+	PMADDUBSW X14, X1
+	PACKUSWB  X1, X1
+	MOVQ      X1, (DI)
+	ADDQ      $16, SI
+	ADDQ      $8, DI
+	INCQ      BX
+	DECQ      CX
+	JNZ       decodeLoop
+
+	MOVQ R12, failed+24(FP)
+	MOVQ R13, block+32(FP)
+	MOVQ R14, mask+40(FP)
+	MOVQ R10, lo+48(FP)
+	MOVQ R11, hi+56(FP)
+	RET
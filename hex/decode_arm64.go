@@ -0,0 +1,45 @@
+//go:build arm64 && !purego
+
+package hex
+
+import "github.com/ericlagergren/subtle/internal/cpu"
+
+var neon = cpu.Register("hex", "neon", cpu.ARM64.HasASIMD)
+
+// decodeBlocksNEON decodes the n 16-byte blocks at src into
+// 8*n bytes at dst, whether or not they are valid.
+//
+// If any block contains an invalid character, failed is 1 and
+// block is the index of the first such block. mlo and mhi hold
+// its validity mask (0xff for each valid character, 0x00
+// otherwise) and lo and hi its characters, in little-endian
+// order.
+//
+//go:noescape
+func decodeBlocksNEON(dst, src *byte, n int) (failed, block int, mlo, mhi, lo, hi uint64)
+
+// decodeAccel decodes as many 16-byte blocks of src as possible
+// using SIMD instructions and returns the number of bytes of
+// src consumed.
+//
+// If the blocks contain an invalid character, decodeAccel also
+// returns the number of bytes decoded before it and an
+// InvalidByteError.
+//
+// decodeAccel runs in constant time for the length of src.
+func decodeAccel(dst, src []byte) (int, int, error) {
+	n := len(src) / 16
+	if n == 0 || !neon.Enabled() {
+		return 0, 0, nil
+	}
+	_ = dst[8*n-1] // bounds check
+	failed, block, mlo, mhi, lo, hi := decodeBlocksNEON(&dst[0], &src[0], n)
+
+	// Collect one bit per character, like PMOVMSKB.
+	var valid uint16
+	for i := 0; i < 8; i++ {
+		valid |= uint16(mlo>>(8*i)&1) << i
+		valid |= uint16(mhi>>(8*i)&1) << (i + 8)
+	}
+	return accelResult(n, failed, block, valid, lo, hi)
+}
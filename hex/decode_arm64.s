@@ -0,0 +1,99 @@
+//go:build arm64 && !purego
+
+#include "textflag.h"
+
+// Each block of 16 characters is decoded like the SSSE3 version
+// in decode_amd64.s:
+//
+//    d  = c - '0'
+//    md = d <= 9            (unsigned)
+//    l  = (c | 0x20) - 'a'
+//    ml = l <= 5            (unsigned)
+//    v  = d&md | (l+10)&ml
+//
+// The character is valid iff md|ml is set. The even (high) and
+// odd (low) nibbles are then separated with UZP1 and UZP2 and
+// combined with a shift and an OR.
+
+// func decodeBlocksNEON(dst, src *byte, n int) (failed, block int, mlo, mhi, lo, hi uint64)
+//
+// Every block is decoded and written to dst, valid or not. The
+// first invalid block is recorded with CSEL, so the loop does not
+// branch on the input.
+TEXT ·decodeBlocksNEON(SB), NOSPLIT, $0-72
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD n+16(FP), R2
+
+	MOVD $0x30, R3
+	VDUP R3, V8.B16  // '0'
+	MOVD $9, R3
+	VDUP R3, V9.B16
+	MOVD $0x20, R3
+	VDUP R3, V10.B16
+	MOVD $0x61, R3
+	VDUP R3, V11.B16 // 'a'
+	MOVD $5, R3
+	VDUP R3, V12.B16
+	MOVD $10, R3
+	VDUP R3, V13.B16
+
+	MOVD $0, R9   // index of the current block
+	MOVD $0, R10  // 1 once an invalid block has been seen
+	MOVD $0, R11  // index of the first invalid block
+	MOVD $-1, R12 // validity mask of the first invalid block
+	MOVD $-1, R13
+	MOVD $0, R19  // characters of the first invalid block
+	MOVD $0, R20
+
+loop:
+	VLD1.P 16(R1), [V0.B16]
+	VMOV   V0.D[0], R4
+	VMOV   V0.D[1], R5
+
+	VSUB  V8.B16, V0.B16, V1.B16
+	VUMIN V9.B16, V1.B16, V2.B16
+	VCMEQ V1.B16, V2.B16, V2.B16
+	VORR  V10.B16, V0.B16, V3.B16
+	VSUB  V11.B16, V3.B16, V3.B16
+	VUMIN V12.B16, V3.B16, V4.B16
+	VCMEQ V3.B16, V4.B16, V4.B16
+
+	// R3 = 1 iff this is the first invalid block.
+	VORR V2.B16, V4.B16, V5.B16
+	VMOV V5.D[0], R6
+	VMOV V5.D[1], R7
+	AND  R6, R7, R8
+	CMN  $1, R8
+	CSET NE, R3
+	BIC  R10, R3, R3
+	ORR  R3, R10, R10
+	CMP  $0, R3
+	CSEL NE, R9, R11, R11
+	CSEL NE, R6, R12, R12
+	CSEL NE, R7, R13, R13
+	CSEL NE, R4, R19, R19
+	CSEL NE, R5, R20, R20
+
+	VAND  V2.B16, V1.B16, V1.B16
+	VADD  V13.B16, V3.B16, V3.B16
+	VAND  V4.B16, V3.B16, V3.B16
+	VORR  V3.B16, V1.B16, V1.B16
+	VUZP1 V1.B16, V1.B16, V6.B16
+	VUZP2 V1.B16, V1.B16, V7.B16
+	VSHL  $4, V6.B16, V6.B16
+	VORR  V7.B16, V6.B16, V6.B16
+	VMOV  V6.D[0], R8
+	MOVD.P R8, 8(R0)
+
+	ADD  $1, R9
+	SUBS $1, R2
+	BNE  loop
+
+	MOVD R10, failed+24(FP)
+	MOVD R11, block+32(FP)
+	MOVD R12, mlo+40(FP)
+	MOVD R13, mhi+48(FP)
+	MOVD R19, lo+56(FP)
+	MOVD R20, hi+64(FP)
+	RET
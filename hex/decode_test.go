@@ -0,0 +1,94 @@
+package hex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ericlagergren/subtle/internal/cpu"
+)
+
+// forEachImpl runs fn once with every accelerated
+// implementation disabled and once for each available
+// implementation.
+func forEachImpl(t *testing.T, fn func(t *testing.T)) {
+	impls := cpu.Impls("hex")
+	t.Run("generic", func(t *testing.T) {
+		for _, impl := range impls {
+			defer impl.Disable()()
+		}
+		fn(t)
+	})
+	for _, impl := range impls {
+		if !impl.Available {
+			t.Logf("%s: not supported", impl.Name)
+			continue
+		}
+		t.Run(impl.Name, fn)
+	}
+}
+
+func TestDecodeImpls(t *testing.T) {
+	forEachImpl(t, func(t *testing.T) {
+		t.Run("Decode", TestDecode)
+		t.Run("DecodeErr", TestDecodeErr)
+		t.Run("DecodeString", TestDecodeString)
+		t.Run("DecodeStringErr", TestDecodeStringErr)
+		t.Run("DecodeExhaustive", TestDecodeExhaustive)
+		t.Run("DecodeRandom", testDecodeRandom)
+	})
+}
+
+// testDecodeRandom compares Decode against encoding/hex for
+// random valid and invalid inputs.
+func testDecodeRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const chars = "0123456789abcdefABCDEF"
+	for n := 0; n < 100; n++ {
+		for iter := 0; iter < 50; iter++ {
+			src := make([]byte, n)
+			for i := range src {
+				src[i] = chars[rng.Intn(len(chars))]
+			}
+			if n > 0 && iter%2 == 1 {
+				// Corrupt a random character.
+				src[rng.Intn(n)] = byte(rng.Intn(256))
+			}
+			if n > 0 && iter%4 == 3 {
+				// And sometimes another, so that more than one
+				// block can be invalid.
+				src[rng.Intn(n)] = byte(rng.Intn(256))
+			}
+
+			want := make([]byte, n/2)
+			wantN, wantErr := hex.Decode(want, src)
+			got := make([]byte, n/2)
+			gotN, gotErr := Decode(got, src)
+			if gotN != wantN || !bytes.Equal(got[:gotN], want[:wantN]) || !errors.Is(gotErr, wantErr) {
+				t.Fatalf("Decode(%q) = (%d, %x, %v), want (%d, %x, %v)",
+					src, gotN, got[:gotN], gotErr, wantN, want[:wantN], wantErr)
+			}
+
+			// In place.
+			buf := append([]byte(nil), src...)
+			gotN, gotErr = Decode(buf, buf)
+			if gotN != wantN || !bytes.Equal(buf[:gotN], want[:wantN]) || !errors.Is(gotErr, wantErr) {
+				t.Fatalf("Decode(%q) in place = (%d, %x, %v), want (%d, %x, %v)",
+					src, gotN, buf[:gotN], gotErr, wantN, want[:wantN], wantErr)
+			}
+
+			// DecodeInPlace.
+			buf = append(buf[:0], src...)
+			gotN, gotErr = DecodeInPlace(buf)
+			if gotN != wantN || !bytes.Equal(buf[:gotN], want[:wantN]) || !errors.Is(gotErr, wantErr) {
+				t.Fatalf("DecodeInPlace(%q) = (%d, %x, %v), want (%d, %x, %v)",
+					src, gotN, buf[:gotN], gotErr, wantN, want[:wantN], wantErr)
+			}
+			if !bytes.Equal(buf[gotN:], make([]byte, n-gotN)) {
+				t.Fatalf("DecodeInPlace(%q): remainder not wiped: %x", src, buf[gotN:])
+			}
+		}
+	}
+}
@@ -1,3 +1,7 @@
 // Package hex implements constant-time hexadecimal encoding and
 // decoding.
+//
+// Decode uses SIMD instructions on amd64 CPUs with SSSE3 and on
+// arm64 CPUs with NEON (ASIMD). Other architectures, and builds
+// with the purego tag, use the portable implementation.
 package hex
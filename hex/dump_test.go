@@ -0,0 +1,107 @@
+package hex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestToChar(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		b := byte(i)
+		want := byte('.')
+		if b >= 32 && b <= 126 {
+			want = b
+		}
+		if got := toChar(b); got != want {
+			t.Fatalf("toChar(%#x): expected %q, got %q", b, want, got)
+		}
+	}
+}
+
+func TestDumper(t *testing.T) {
+	var in [40]byte
+	for i := range in {
+		in[i] = byte(i + 30)
+	}
+
+	for n := 0; n <= len(in); n++ {
+		for stride := 1; stride < len(in); stride++ {
+			var out bytes.Buffer
+			dumper := Dumper(&out)
+			done := 0
+			for done < n {
+				todo := done + stride
+				if todo > n {
+					todo = n
+				}
+				dumper.Write(in[done:todo])
+				done = todo
+			}
+			dumper.Close()
+
+			var want bytes.Buffer
+			stdDumper := hex.Dumper(&want)
+			stdDumper.Write(in[:n])
+			stdDumper.Close()
+			if !bytes.Equal(out.Bytes(), want.Bytes()) {
+				t.Fatalf("stride: %d, len: %d\nwant:\n%s\ngot:\n%s",
+					stride, n, want.Bytes(), out.Bytes())
+			}
+		}
+	}
+}
+
+func TestDumperDoubleClose(t *testing.T) {
+	var out bytes.Buffer
+	dumper := Dumper(&out)
+
+	dumper.Write([]byte(`gopher`))
+	dumper.Close()
+	dumper.Close()
+	dumper.Write([]byte(`gopher`))
+	dumper.Close()
+
+	expected := "00000000  67 6f 70 68 65 72                                 |gopher|\n"
+	if out.String() != expected {
+		t.Fatalf("got:\n%#v\nwant:\n%#v", out.String(), expected)
+	}
+}
+
+func TestDump(t *testing.T) {
+	var in [40]byte
+	for i := range in {
+		in[i] = byte(i + 30)
+	}
+	for n := 0; n <= len(in); n++ {
+		if got, want := Dump(in[:n]), hex.Dump(in[:n]); got != want {
+			t.Fatalf("len: %d\nwant:\n%s\ngot:\n%s", n, want, got)
+		}
+	}
+}
+
+func TestRedactedDump(t *testing.T) {
+	in := []byte("0123456789abcdefghij")
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{4, "" +
+			"00000000  30 31 32 33 ** ** ** **  ** ** ** ** ** ** ** **  |0123************|\n" +
+			"00000010  67 68 69 6a                                       |ghij|\n"},
+		{0, "" +
+			"00000000  ** ** ** ** ** ** ** **  ** ** ** ** ** ** ** **  |****************|\n" +
+			"00000010  ** ** ** **                                       |****|\n"},
+		// At least half of the input is always masked.
+		{100, "" +
+			"00000000  30 31 32 33 34 ** ** **  ** ** ** ** ** ** ** 66  |01234**********f|\n" +
+			"00000010  67 68 69 6a                                       |ghij|\n"},
+	} {
+		if got := RedactedDump(in, tc.n); got != tc.want {
+			t.Errorf("%d:\nwant:\n%s\ngot:\n%s", tc.n, tc.want, got)
+		}
+	}
+	if got := RedactedDump(nil, 4); got != "" {
+		t.Errorf("expected empty dump, got %q", got)
+	}
+}
@@ -0,0 +1,390 @@
+package hex
+
+import (
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+// An Encoding is a hexadecimal encoding scheme.
+//
+// It combines the options supported by this package (letter
+// case, a "0x" prefix, separators between groups of bytes, and
+// uniform errors) so that they compose. Encodings are created
+// from StdEncoding or UpperEncoding using the With methods.
+//
+// Every Encoding method runs in constant time for the length of
+// its input.
+type Encoding struct {
+	alpha  uint // lowerAlpha or upperAlpha
+	prefix bool // "0x" prefix
+	group  int  // bytes per group, or zero for no separators
+	sep    byte // separator between groups
+	strict bool // return ErrCorrupt for all errors
+	casing Case // letter case accepted when decoding
+	max    int  // maximum decoded length, or -1 for no limit
+}
+
+// Case is a policy for the letter case accepted when decoding.
+type Case int
+
+const (
+	// AnyCase accepts both lowercase and uppercase characters,
+	// including mixed case. It is the default.
+	AnyCase Case = iota
+	// LowerCase accepts only lowercase characters.
+	LowerCase
+	// UpperCase accepts only uppercase characters.
+	UpperCase
+	// ConsistentCase accepts either lowercase or uppercase
+	// characters, but not both.
+	ConsistentCase
+)
+
+// StdEncoding is the lowercase hexadecimal encoding used by the
+// package-level functions.
+var StdEncoding = &Encoding{alpha: lowerAlpha, max: -1}
+
+// UpperEncoding is the uppercase hexadecimal encoding.
+var UpperEncoding = &Encoding{alpha: upperAlpha, max: -1}
+
+// WithUpper creates a new encoding identical to enc except that
+// it encodes using uppercase hexadecimal characters.
+//
+// It does not change the case accepted when decoding. See
+// WithDecodeCase.
+func (enc Encoding) WithUpper() *Encoding {
+	enc.alpha = upperAlpha
+	return &enc
+}
+
+// WithLower creates a new encoding identical to enc except that
+// it encodes using lowercase hexadecimal characters.
+//
+// It does not change the case accepted when decoding. See
+// WithDecodeCase.
+func (enc Encoding) WithLower() *Encoding {
+	enc.alpha = lowerAlpha
+	return &enc
+}
+
+// WithPrefix creates a new encoding identical to enc except that
+// encoded data begins with "0x".
+//
+// Decoding requires the prefix, but accepts both "0x" and "0X".
+func (enc Encoding) WithPrefix() *Encoding {
+	enc.prefix = true
+	return &enc
+}
+
+// WithSeparator creates a new encoding identical to enc except
+// that sep is inserted between each group of group bytes, like
+// EncodeGrouped.
+//
+// Decoding requires sep to appear exactly where the encoder
+// would write it.
+//
+// WithSeparator panics if group <= 0 or sep is a hexadecimal
+// character.
+func (enc Encoding) WithSeparator(sep byte, group int) *Encoding {
+	if group <= 0 {
+		panic("hex: invalid group size")
+	}
+	if validHexChar(sep) {
+		panic("hex: invalid separator")
+	}
+	enc.sep = sep
+	enc.group = group
+	return &enc
+}
+
+// WithDecodeCase creates a new encoding identical to enc except
+// that decoding only accepts letters allowed by the policy c.
+// A letter in the wrong case is reported as an InvalidByteError.
+//
+// WithDecodeCase panics if c is not a valid Case.
+func (enc Encoding) WithDecodeCase(c Case) *Encoding {
+	if c < AnyCase || c > ConsistentCase {
+		panic("hex: invalid case policy")
+	}
+	enc.casing = c
+	return &enc
+}
+
+// WithLimit creates a new encoding identical to enc except
+// that decoding input that could decode to more than max bytes
+// fails with ErrTooLarge before anything is decoded or
+// allocated.
+//
+// The limit only depends on the length of the input, so
+// ErrTooLarge is returned even if the encoding is strict.
+//
+// WithLimit panics if max < 0.
+func (enc Encoding) WithLimit(max int) *Encoding {
+	if max < 0 {
+		panic("hex: invalid limit")
+	}
+	enc.max = max
+	return &enc
+}
+
+// Strict creates a new encoding identical to enc except that
+// decoding does not reveal where or why it failed, like
+// DecodeStrict.
+func (enc Encoding) Strict() *Encoding {
+	enc.strict = true
+	return &enc
+}
+
+// prefixLen returns the length of the encoding's prefix.
+func (enc *Encoding) prefixLen() int {
+	if enc.prefix {
+		return 2
+	}
+	return 0
+}
+
+// EncodedLen returns the length of an encoding of n source
+// bytes.
+func (enc *Encoding) EncodedLen(n int) int {
+	m := EncodedLen(n)
+	if enc.group > 0 {
+		m = GroupedEncodedLen(n, enc.group)
+	}
+	return enc.prefixLen() + m
+}
+
+// Encode encodes src into EncodedLen(len(src)) bytes of dst.
+// As a convenience, it returns the number of bytes written to
+// dst, but this value is always EncodedLen(len(src)).
+func (enc *Encoding) Encode(dst, src []byte) int {
+	n := enc.EncodedLen(len(src))
+	if enc.prefix {
+		dst[0] = '0'
+		dst[1] = 'x'
+		dst = dst[2:]
+	}
+	if enc.group > 0 {
+		encodeGrouped(dst, src, enc.group, enc.sep, enc.alpha)
+	} else {
+		encode(dst, src, enc.alpha)
+	}
+	return n
+}
+
+// AppendEncode appends the encoding of src to dst and returns
+// the extended buffer.
+func (enc *Encoding) AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, enc.EncodedLen(len(src)))
+	enc.Encode(out, src)
+	return ret
+}
+
+// EncodeToString returns the encoding of src.
+func (enc *Encoding) EncodeToString(src []byte) string {
+	dst := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(dst, src)
+	return string(dst)
+}
+
+// DecodedLen returns the maximum length of the bytes represented
+// by n encoded bytes.
+func (enc *Encoding) DecodedLen(n int) int {
+	n -= enc.prefixLen()
+	if n <= 0 {
+		return 0
+	}
+	if enc.group > 0 {
+		// Every separator is preceded by a full group.
+		n -= (n - 1) / (2*enc.group + 1)
+	}
+	return DecodedLen(n)
+}
+
+// Decode decodes src into at most DecodedLen(len(src)) bytes,
+// returning the actual number of bytes written to dst.
+//
+// If the input is malformed, Decode returns the number of bytes
+// decoded before the error, unless the encoding is strict (see
+// Strict).
+func (enc *Encoding) Decode(dst, src []byte) (int, error) {
+	if err := enc.checkLimit(len(src)); err != nil {
+		return 0, err
+	}
+	n, err := enc.decode(dst, src)
+	if enc.strict {
+		m := enc.DecodedLen(len(src))
+		if m > len(dst) {
+			m = len(dst)
+		}
+		return strictResult(dst[:m], n, err)
+	}
+	return n, err
+}
+
+func (enc *Encoding) decode(dst, src []byte) (int, error) {
+	var prefixErr error
+	if enc.prefix {
+		// Decode the rest of src even if the prefix is wrong so
+		// that the time taken does not depend on where the
+		// error is.
+		prefixErr = checkPrefix(src)
+		if len(src) < 2 {
+			return 0, prefixErr
+		}
+		src = src[2:]
+	}
+	var n int
+	var err error
+	if enc.group > 0 {
+		n, err = decodeGrouped(dst, src, enc.group, enc.sep, enc.casing)
+	} else {
+		n, err = decodeCase(dst, src, enc.casing)
+	}
+	if prefixErr != nil {
+		return 0, prefixErr
+	}
+	return n, err
+}
+
+// AppendDecode appends the bytes represented by src to dst and
+// returns the extended buffer.
+//
+// If the input is malformed, AppendDecode returns dst extended
+// by the bytes decoded before the error, unless the encoding is
+// strict (see Strict).
+func (enc *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	if err := enc.checkLimit(len(src)); err != nil {
+		return dst, err
+	}
+	ret, out := subtle.SliceForAppend(dst, enc.DecodedLen(len(src)))
+	n, err := enc.Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by s.
+//
+// If the input is malformed, DecodeString returns the bytes
+// decoded before the error, unless the encoding is strict (see
+// Strict).
+func (enc *Encoding) DecodeString(s string) ([]byte, error) {
+	if err := enc.checkLimit(len(s)); err != nil {
+		return nil, err
+	}
+	src := []byte(s)
+	n, err := enc.Decode(src, src)
+	return src[:n], err
+}
+
+// checkLimit returns ErrTooLarge if n encoded bytes would
+// decode to more than the encoding's limit.
+func (enc *Encoding) checkLimit(n int) error {
+	if enc.max >= 0 && enc.DecodedLen(n) > enc.max {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// checkPrefix checks that src begins with "0x" or "0X".
+//
+// checkPrefix runs in constant time.
+func checkPrefix(src []byte) error {
+	if len(src) < 2 {
+		if len(src) == 1 && src[0] != '0' {
+			return InvalidByteError(src[0])
+		}
+		return ErrLength
+	}
+	ok0 := subtle.ConstantTimeByteEq(src[0], '0')
+	ok1 := subtle.ConstantTimeByteEq(src[1]|0x20, 'x')
+	if ok0&ok1 == 0 {
+		c := subtle.ConstantTimeSelect(ok0, int(src[1]), int(src[0]))
+		return InvalidByteError(c)
+	}
+	return nil
+}
+
+// decodeGrouped decodes src, which must contain sep between each
+// group of group bytes.
+//
+// decodeGrouped runs in constant time for the length of src.
+func decodeGrouped(dst, src []byte, group int, sep byte, c Case) (int, error) {
+	var arr [128]byte
+	buf := arr[:]
+	if len(src) > len(arr) {
+		buf = make([]byte, len(src))
+	}
+	defer subtle.Wipe(buf)
+
+	// Copy the hexadecimal characters into buf and check the
+	// separators, recording the first bad one in constant time.
+	//
+	// The positions of the separators only depend on the length
+	// of src. A separator in the final position is not allowed,
+	// so it is left for Decode to reject.
+	stride := 2*group + 1
+	var bad alphabet.FirstError
+	var badChar int
+	j := 0
+	for i, c := range src {
+		if (i+1)%stride != 0 || i == len(src)-1 {
+			buf[j] = c
+			j++
+			continue
+		}
+		ne := subtle.ConstantTimeByteEq(c, sep) ^ 1
+		// The separator precedes byte (i+1)/stride*group.
+		first := bad.Check(ne, (i+1)/stride*group)
+		badChar = subtle.ConstantTimeSelect(first, int(c), badChar)
+	}
+
+	n, err := decodeCase(dst, buf[:j], c)
+	if bad.Failed() != 0 && (err == nil || bad.Index() <= n) {
+		return bad.Index(), InvalidByteError(badChar)
+	}
+	return n, err
+}
+
+// decodeCase is like Decode, but rejects letters that are not
+// allowed by the case policy c.
+//
+// decodeCase runs in constant time for the length of src.
+func decodeCase(dst, src []byte, c Case) (int, error) {
+	if c == AnyCase {
+		return Decode(dst, src)
+	}
+
+	// Find the first character that is either a letter in the
+	// wrong case or not a hexadecimal character at all. Letters
+	// are in the wrong case after a letter in the other case
+	// has been seen, so the policy just sets the initial state.
+	var lower, upper int
+	switch c {
+	case LowerCase:
+		lower = 1
+	case UpperCase:
+		upper = 1
+	}
+	var bad alphabet.FirstError
+	var badChar, badCase int
+	for i, x := range src {
+		isLower := subtle.ConstantTimeByteLessOrEq('a', x) &
+			subtle.ConstantTimeByteLessOrEq(x, 'f')
+		isUpper := subtle.ConstantTimeByteLessOrEq('A', x) &
+			subtle.ConstantTimeByteLessOrEq(x, 'F')
+		wrong := isLower&upper | isUpper&lower
+		first := bad.Check(wrong|(isHexChar(x)^1), i/2)
+		badChar = subtle.ConstantTimeSelect(first, int(x), badChar)
+		badCase = subtle.ConstantTimeSelect(first, wrong, badCase)
+		lower |= isLower
+		upper |= isUpper
+	}
+
+	// src may alias dst, so only decode after checking src. If
+	// the first bad character is not a hexadecimal character,
+	// Decode reports it.
+	n, err := Decode(dst, src)
+	if badCase != 0 {
+		return bad.Index(), InvalidByteError(badChar)
+	}
+	return n, err
+}
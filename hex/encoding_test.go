@@ -0,0 +1,206 @@
+package hex
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	src := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}
+	for _, tt := range []struct {
+		enc *Encoding
+		out string
+	}{
+		{StdEncoding, "deadbeef01"},
+		{UpperEncoding, "DEADBEEF01"},
+		{UpperEncoding.WithLower(), "deadbeef01"},
+		{StdEncoding.WithUpper(), "DEADBEEF01"},
+		{StdEncoding.WithPrefix(), "0xdeadbeef01"},
+		{UpperEncoding.WithPrefix(), "0xDEADBEEF01"},
+		{StdEncoding.WithSeparator(':', 1), "de:ad:be:ef:01"},
+		{StdEncoding.WithSeparator(' ', 2), "dead beef 01"},
+		{UpperEncoding.WithSeparator('-', 4), "DEADBEEF-01"},
+		{UpperEncoding.WithSeparator('-', 5), "DEADBEEF01"},
+		{StdEncoding.WithPrefix().WithSeparator('_', 2).Strict(), "0xdead_beef_01"},
+	} {
+		if n := tt.enc.EncodedLen(len(src)); n != len(tt.out) {
+			t.Errorf("%q: EncodedLen: expected %d, got %d", tt.out, len(tt.out), n)
+		}
+		if got := tt.enc.EncodeToString(src); got != tt.out {
+			t.Errorf("EncodeToString: expected %q, got %q", tt.out, got)
+		}
+		if got := tt.enc.AppendEncode([]byte("x"), src); string(got) != "x"+tt.out {
+			t.Errorf("AppendEncode: expected %q, got %q", "x"+tt.out, got)
+		}
+
+		if n := tt.enc.DecodedLen(len(tt.out)); n != len(src) {
+			t.Errorf("%q: DecodedLen: expected %d, got %d", tt.out, len(src), n)
+		}
+		got, err := tt.enc.DecodeString(tt.out)
+		if err != nil || !bytes.Equal(got, src) {
+			t.Errorf("DecodeString(%q) = (%x, %v)", tt.out, got, err)
+		}
+		got, err = tt.enc.AppendDecode([]byte("x"), []byte(tt.out))
+		if err != nil || !bytes.Equal(got, append([]byte("x"), src...)) {
+			t.Errorf("AppendDecode(%q) = (%x, %v)", tt.out, got, err)
+		}
+	}
+}
+
+func TestEncodingEmpty(t *testing.T) {
+	for _, enc := range []*Encoding{
+		StdEncoding,
+		StdEncoding.WithPrefix(),
+		StdEncoding.WithSeparator(':', 1),
+	} {
+		s := enc.EncodeToString(nil)
+		got, err := enc.DecodeString(s)
+		if err != nil || len(got) != 0 {
+			t.Errorf("DecodeString(%q) = (%x, %v)", s, got, err)
+		}
+	}
+}
+
+func TestEncodingDecodeErr(t *testing.T) {
+	prefix := StdEncoding.WithPrefix()
+	colon := StdEncoding.WithSeparator(':', 1)
+	pair := StdEncoding.WithSeparator(':', 2)
+	for _, tt := range []struct {
+		enc *Encoding
+		in  string
+		out string
+		err error
+	}{
+		{prefix, "", "", ErrLength},
+		{prefix, "0", "", ErrLength},
+		{prefix, "1", "", InvalidByteError('1')},
+		{prefix, "dead", "", InvalidByteError('d')},
+		{prefix, "0dead", "", InvalidByteError('d')},
+		{prefix, "0X", "", nil},
+		{prefix, "0Xdead", "\xde\xad", nil},
+		{prefix, "0xdeag", "\xde", InvalidByteError('g')},
+		{prefix, "0xdea", "\xde", ErrLength},
+
+		{colon, "de:ad", "\xde\xad", nil},
+		{colon, "dead", "\xde", InvalidByteError('a')},
+		{colon, "de;ad", "\xde", InvalidByteError(';')},
+		{colon, "de:ad:", "\xde\xad", InvalidByteError(':')},
+		{colon, "de:a", "\xde", ErrLength},
+		{colon, "dg:ad", "", InvalidByteError('g')},
+		{colon, "de:ag;bb", "\xde", InvalidByteError('g')},
+		{colon, "de;ad:bg", "\xde", InvalidByteError(';')},
+
+		{pair, "dead:beef", "\xde\xad\xbe\xef", nil},
+		{pair, "de:adbeef", "\xde", InvalidByteError(':')},
+		{pair, "deadXbeef", "\xde\xad", InvalidByteError('X')},
+	} {
+		out := make([]byte, len(tt.in))
+		n, err := tt.enc.Decode(out, []byte(tt.in))
+		if string(out[:n]) != tt.out || err != tt.err {
+			t.Errorf("Decode(%q) = (%q, %v), want (%q, %v)",
+				tt.in, out[:n], err, tt.out, tt.err)
+		}
+
+		strict := tt.enc.Strict()
+		n, err = strict.Decode(out, []byte(tt.in))
+		if tt.err == nil {
+			continue
+		}
+		if n != 0 || err != ErrCorrupt {
+			t.Errorf("strict Decode(%q) = (%d, %v), want (0, %v)", tt.in, n, err, ErrCorrupt)
+		}
+	}
+}
+
+func TestEncodingInvalidSeparator(t *testing.T) {
+	for _, sep := range []byte("0aF") {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected a panic", sep)
+				}
+			}()
+			StdEncoding.WithSeparator(sep, 1)
+		}()
+	}
+}
+
+func TestEncodingDecodeCase(t *testing.T) {
+	lower := StdEncoding.WithDecodeCase(LowerCase)
+	upper := StdEncoding.WithDecodeCase(UpperCase)
+	consistent := StdEncoding.WithDecodeCase(ConsistentCase)
+	grouped := StdEncoding.WithSeparator(':', 1).WithDecodeCase(LowerCase)
+	for _, tt := range []struct {
+		enc *Encoding
+		in  string
+		out string
+		err error
+	}{
+		{lower, "0123abcd", "\x01\x23\xab\xcd", nil},
+		{lower, "0123abCd", "\x01\x23\xab", InvalidByteError('C')},
+		{lower, "01A3abcd", "\x01", InvalidByteError('A')},
+		{lower, "01g3abCd", "\x01", InvalidByteError('g')},
+		{lower, "01a3abgD", "\x01\xa3\xab", InvalidByteError('g')},
+		{lower, "01a3abDg", "\x01\xa3\xab", InvalidByteError('D')},
+
+		{upper, "0123ABCD", "\x01\x23\xab\xcd", nil},
+		{upper, "0123ABcD", "\x01\x23\xab", InvalidByteError('c')},
+		{upper, "0123ABC", "\x01\x23\xab", ErrLength},
+
+		{consistent, "0123abcd", "\x01\x23\xab\xcd", nil},
+		{consistent, "0123ABCD", "\x01\x23\xab\xcd", nil},
+		{consistent, "0123456789", "\x01\x23\x45\x67\x89", nil},
+		{consistent, "0123aBcd", "\x01\x23", InvalidByteError('B')},
+		{consistent, "01E3abcd", "\x01\xe3", InvalidByteError('a')},
+
+		{grouped, "01:ab:cd", "\x01\xab\xcd", nil},
+		{grouped, "01:aB:cd", "\x01", InvalidByteError('B')},
+		{grouped, "01;ab:cD", "\x01", InvalidByteError(';')},
+		{grouped, "01:aB;cd", "\x01", InvalidByteError('B')},
+		{grouped, "01:ab;cD", "\x01\xab", InvalidByteError(';')},
+	} {
+		out := make([]byte, len(tt.in))
+		n, err := tt.enc.Decode(out, []byte(tt.in))
+		if string(out[:n]) != tt.out || err != tt.err {
+			t.Errorf("Decode(%q) = (%q, %v), want (%q, %v)",
+				tt.in, out[:n], err, tt.out, tt.err)
+		}
+
+		// In place.
+		got, err := tt.enc.DecodeString(tt.in)
+		if string(got) != tt.out || err != tt.err {
+			t.Errorf("DecodeString(%q) = (%q, %v), want (%q, %v)",
+				tt.in, got, err, tt.out, tt.err)
+		}
+	}
+}
+
+func TestEncodingLimit(t *testing.T) {
+	enc := StdEncoding.WithLimit(4)
+	for _, enc := range []*Encoding{enc, enc.Strict(), enc.WithPrefix().WithSeparator(':', 1)} {
+		for n := 0; n < 10; n++ {
+			src := make([]byte, n)
+			s := enc.EncodeToString(src)
+			var want error
+			if n > 4 {
+				want = ErrTooLarge
+			}
+			if _, err := enc.DecodeString(s); err != want {
+				t.Errorf("DecodeString(%q): expected %v, got %v", s, want, err)
+			}
+			if _, err := enc.AppendDecode(nil, []byte(s)); err != want {
+				t.Errorf("AppendDecode(%q): expected %v, got %v", s, want, err)
+			}
+			if _, err := enc.Decode(make([]byte, 16), []byte(s)); err != want {
+				t.Errorf("Decode(%q): expected %v, got %v", s, want, err)
+			}
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		enc.DecodeString("0011223344")
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,46 @@
+package hex
+
+import "github.com/ericlagergren/subtle"
+
+// DecodeFingerprint is like Decode, but skips separators.
+//
+// The separators are ':', '-', and ASCII whitespace (' ', '\t',
+// '\n', and '\r'), which allows decoding fingerprints like
+// "aa:bb:cc:dd" and "AA BB CC DD" produced by TLS and SSH tools.
+// Separators may appear anywhere in src, including between the
+// two characters of a byte.
+//
+// Separators are skipped without branching, so DecodeFingerprint
+// runs in constant time for the length of src. However, the
+// number of separators is revealed by the result.
+func DecodeFingerprint(dst, src []byte) (int, error) {
+	var arr [128]byte
+	buf := arr[:]
+	if len(src) > len(arr) {
+		buf = make([]byte, len(src))
+	}
+	defer subtle.Wipe(buf)
+
+	// Compact src into buf. Each character is written to buf[j],
+	// but j only advances past characters that are not
+	// separators.
+	j := 0
+	for _, c := range src {
+		buf[j] = c
+		j += isSeparator(c) ^ 1
+	}
+	return Decode(dst, buf[:j])
+}
+
+// isSeparator returns 1 if c is a fingerprint separator and
+// 0 otherwise.
+//
+// isSeparator runs in constant time.
+func isSeparator(c byte) int {
+	return subtle.ConstantTimeByteEq(c, ':') |
+		subtle.ConstantTimeByteEq(c, '-') |
+		subtle.ConstantTimeByteEq(c, ' ') |
+		subtle.ConstantTimeByteEq(c, '\t') |
+		subtle.ConstantTimeByteEq(c, '\n') |
+		subtle.ConstantTimeByteEq(c, '\r')
+}
@@ -0,0 +1,46 @@
+package hex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeFingerprint(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out string
+		err error
+	}{
+		{"", "", nil},
+		{"aa:bb:cc:dd", "\xaa\xbb\xcc\xdd", nil},
+		{"AA BB CC", "\xaa\xbb\xcc", nil},
+		{"aa-bb\tcc\r\ndd", "\xaa\xbb\xcc\xdd", nil},
+		{"a:a", "\xaa", nil},
+		{"::::", "", nil},
+		{"aabb", "\xaa\xbb", nil},
+		{"aa:b", "\xaa", ErrLength},
+		{"aa:bg", "\xaa", InvalidByteError('g')},
+		{"aa;bb", "\xaa", InvalidByteError(';')},
+		{strings.Repeat("01:", 100) + "ff", strings.Repeat("\x01", 100) + "\xff", nil},
+	} {
+		out := make([]byte, len(tt.in))
+		n, err := DecodeFingerprint(out, []byte(tt.in))
+		if string(out[:n]) != tt.out || err != tt.err {
+			t.Errorf("DecodeFingerprint(%q) = (%q, %v), want (%q, %v)",
+				tt.in, out[:n], err, tt.out, tt.err)
+		}
+	}
+}
+
+func TestIsSeparator(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		c := byte(i)
+		want := 0
+		if strings.IndexByte(":- \t\n\r", c) >= 0 {
+			want = 1
+		}
+		if got := isSeparator(c); got != want {
+			t.Fatalf("isSeparator(%q): expected %d, got %d", c, want, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hex
+
+import (
+	"encoding/binary"
+	"errors"
+	"unsafe"
+
+	"github.com/ericlagergren/subtle"
+)
+
+// ErrWrongLength is returned when decoding a hexadecimal string
+// that does not have the exact expected length.
+var ErrWrongLength = errors.New("hex: hexadecimal string has the wrong length")
+
+// Encode16 returns the hexadecimal encoding of src.
+//
+// Encode16 runs in constant time.
+func Encode16(src [16]byte) (dst [32]byte) {
+	Encode(dst[:], src[:])
+	return
+}
+
+// Encode32 returns the hexadecimal encoding of src.
+//
+// Encode32 runs in constant time.
+func Encode32(src [32]byte) (dst [64]byte) {
+	Encode(dst[:], src[:])
+	return
+}
+
+// Encode64 returns the hexadecimal encoding of src.
+//
+// Encode64 runs in constant time.
+func Encode64(src [64]byte) (dst [128]byte) {
+	Encode(dst[:], src[:])
+	return
+}
+
+// Decode16 decodes the 32-character hexadecimal string s.
+//
+// It returns ErrWrongLength if len(s) != 32. If s is malformed,
+// Decode16 returns the zero array and an error.
+//
+// Decode16 runs in constant time.
+func Decode16(s string) ([16]byte, error) {
+	var dst [16]byte
+	if err := decodeFixed(dst[:], s); err != nil {
+		return [16]byte{}, err
+	}
+	return dst, nil
+}
+
+// Decode32 decodes the 64-character hexadecimal string s.
+//
+// It returns ErrWrongLength if len(s) != 64. If s is malformed,
+// Decode32 returns the zero array and an error.
+//
+// Decode32 runs in constant time.
+func Decode32(s string) ([32]byte, error) {
+	var dst [32]byte
+	if err := decodeFixed(dst[:], s); err != nil {
+		return [32]byte{}, err
+	}
+	return dst, nil
+}
+
+// Decode64 decodes the 128-character hexadecimal string s.
+//
+// It returns ErrWrongLength if len(s) != 128. If s is malformed,
+// Decode64 returns the zero array and an error.
+//
+// Decode64 runs in constant time.
+func Decode64(s string) ([64]byte, error) {
+	var dst [64]byte
+	if err := decodeFixed(dst[:], s); err != nil {
+		return [64]byte{}, err
+	}
+	return dst, nil
+}
+
+// EncodeUint32 returns the 8-character lowercase hexadecimal
+// encoding of x in big-endian order.
+//
+// EncodeUint32 runs in constant time.
+func EncodeUint32(x uint32) (dst [8]byte) {
+	binary.BigEndian.PutUint64(dst[:], encodeSWAR(x, lowerAlpha))
+	return
+}
+
+// EncodeUint64 returns the 16-character lowercase hexadecimal
+// encoding of x in big-endian order.
+//
+// EncodeUint64 runs in constant time.
+func EncodeUint64(x uint64) (dst [16]byte) {
+	binary.BigEndian.PutUint64(dst[0:8], encodeSWAR(uint32(x>>32), lowerAlpha))
+	binary.BigEndian.PutUint64(dst[8:16], encodeSWAR(uint32(x), lowerAlpha))
+	return
+}
+
+// DecodeUint32 decodes the 8-character hexadecimal string s as
+// a big-endian uint32.
+//
+// It returns ErrWrongLength if len(s) != 8. If s is malformed,
+// DecodeUint32 returns zero and an error.
+//
+// DecodeUint32 runs in constant time.
+func DecodeUint32(s string) (uint32, error) {
+	var buf [4]byte
+	defer subtle.Wipe(buf[:])
+	if err := decodeFixed(buf[:], s); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+// DecodeUint64 decodes the 16-character hexadecimal string s as
+// a big-endian uint64.
+//
+// It returns ErrWrongLength if len(s) != 16. If s is malformed,
+// DecodeUint64 returns zero and an error.
+//
+// DecodeUint64 runs in constant time.
+func DecodeUint64(s string) (uint64, error) {
+	var buf [8]byte
+	defer subtle.Wipe(buf[:])
+	if err := decodeFixed(buf[:], s); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// decodeFixed decodes s into exactly len(dst) bytes.
+//
+// If s is malformed, decodeFixed zeroes dst so that the bytes
+// decoded before the error do not linger on the stack.
+func decodeFixed(dst []byte, s string) error {
+	if len(s) != EncodedLen(len(dst)) {
+		return ErrWrongLength
+	}
+	_, err := Decode(dst, unsafeBytes(s))
+	return wipeOnError(dst, err)
+}
+
+// unsafeBytes returns the contents of s as a byte slice without
+// copying.
+//
+// The result must not be modified.
+func unsafeBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
@@ -0,0 +1,31 @@
+package hex
+
+import "unsafe"
+
+// array is the set of array types supported by DecodeFixed.
+type array interface {
+	~[8]byte | ~[12]byte | ~[16]byte | ~[20]byte | ~[24]byte |
+		~[28]byte | ~[32]byte | ~[48]byte | ~[64]byte
+}
+
+// DecodeFixed decodes the hexadecimal string s into an array of
+// type T.
+//
+// For example:
+//
+//	key, err := hex.DecodeFixed[[32]byte](s)
+//
+// It returns ErrWrongLength if len(s) is not exactly twice the
+// length of T. If s is malformed, DecodeFixed returns the zero
+// array and an error.
+//
+// DecodeFixed runs in constant time.
+func DecodeFixed[T array](s string) (T, error) {
+	var dst T
+	b := unsafe.Slice((*byte)(unsafe.Pointer(&dst)), unsafe.Sizeof(dst))
+	if err := decodeFixed(b, s); err != nil {
+		var zero T
+		return zero, err
+	}
+	return dst, nil
+}
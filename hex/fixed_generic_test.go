@@ -0,0 +1,50 @@
+package hex
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFixed(t *testing.T) {
+	var want [32]byte
+	for i := range want {
+		want[i] = byte(i * 3)
+	}
+	s := hex.EncodeToString(want[:])
+
+	got, err := DecodeFixed[[32]byte](s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("expected %x, got %x", want, got)
+	}
+
+	type key [16]byte
+	k, err := DecodeFixed[key](s[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(k[:]) != string(want[:16]) {
+		t.Fatalf("expected %x, got %x", want[:16], k)
+	}
+
+	for _, tt := range []struct {
+		in  string
+		err error
+	}{
+		{s[:62], ErrWrongLength},
+		{s + "00", ErrWrongLength},
+		{s[:63] + "x", InvalidByteError('x')},
+		{strings.ToUpper(s), nil},
+	} {
+		got, err := DecodeFixed[[32]byte](tt.in)
+		if err != tt.err {
+			t.Errorf("DecodeFixed(%q): expected %v, got %v", tt.in, tt.err, err)
+		}
+		if err != nil && got != [32]byte{} {
+			t.Errorf("DecodeFixed(%q): expected zero array, got %x", tt.in, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package hex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode32(t *testing.T) {
+	var src [32]byte
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	enc := Encode32(src)
+	if want := hex.EncodeToString(src[:]); string(enc[:]) != want {
+		t.Fatalf("Encode32: expected %q, got %q", want, enc)
+	}
+	dec, err := Decode32(string(enc[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != src {
+		t.Fatalf("Decode32: expected %x, got %x", src, dec)
+	}
+}
+
+func TestEncodeDecodeFixed(t *testing.T) {
+	src := make([]byte, 64)
+	for i := range src {
+		src[i] = byte(255 - i)
+	}
+
+	var src16 [16]byte
+	copy(src16[:], src)
+	enc16 := Encode16(src16)
+	if want := hex.EncodeToString(src16[:]); string(enc16[:]) != want {
+		t.Fatalf("Encode16: expected %q, got %q", want, enc16)
+	}
+	dec16, err := Decode16(string(enc16[:]))
+	if err != nil || dec16 != src16 {
+		t.Fatalf("Decode16: got (%x, %v)", dec16, err)
+	}
+
+	var src64 [64]byte
+	copy(src64[:], src)
+	enc64 := Encode64(src64)
+	if want := hex.EncodeToString(src64[:]); string(enc64[:]) != want {
+		t.Fatalf("Encode64: expected %q, got %q", want, enc64)
+	}
+	dec64, err := Decode64(string(enc64[:]))
+	if err != nil || dec64 != src64 {
+		t.Fatalf("Decode64: got (%x, %v)", dec64, err)
+	}
+}
+
+func TestDecodeFixedErr(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		err error
+	}{
+		{"", ErrWrongLength},
+		{strings.Repeat("a", 63), ErrWrongLength},
+		{strings.Repeat("a", 66), ErrWrongLength},
+		{strings.Repeat("a", 63) + "g", InvalidByteError('g')},
+		{"z" + strings.Repeat("a", 63), InvalidByteError('z')},
+	} {
+		dec, err := Decode32(tt.in)
+		if err != tt.err {
+			t.Errorf("Decode32(%q): expected %v, got %v", tt.in, tt.err, err)
+		}
+		if dec != [32]byte{} {
+			t.Errorf("Decode32(%q): expected zero array, got %x", tt.in, dec)
+		}
+	}
+}
+
+// TestDecodeFixedWipe tests that the bytes decoded before an
+// invalid character are wiped.
+func TestDecodeFixedWipe(t *testing.T) {
+	for _, n := range []int{4, 8, 16, 32, 64} {
+		s := strings.Repeat("ab", n-1) + "zz"
+		dst := make([]byte, n)
+		if err := decodeFixed(dst, s); err != InvalidByteError('z') {
+			t.Fatalf("%d: expected %v, got %v", n, InvalidByteError('z'), err)
+		}
+		if !bytes.Equal(dst, make([]byte, n)) {
+			t.Fatalf("%d: dst not wiped: %x", n, dst)
+		}
+	}
+
+	s := strings.Repeat("ab", 7) + "zz"
+	if dec, err := Decode16(s); err == nil || dec != [16]byte{} {
+		t.Errorf("Decode16(%q): got (%x, %v)", s, dec, err)
+	}
+	s = strings.Repeat("ab", 31) + "zz"
+	if dec, err := Decode32(s); err == nil || dec != [32]byte{} {
+		t.Errorf("Decode32(%q): got (%x, %v)", s, dec, err)
+	}
+	s = strings.Repeat("ab", 63) + "zz"
+	if dec, err := Decode64(s); err == nil || dec != [64]byte{} {
+		t.Errorf("Decode64(%q): got (%x, %v)", s, dec, err)
+	}
+}
+
+func TestEncodeDecodeUint(t *testing.T) {
+	for _, x := range []uint64{
+		0, 1, 0xdeadbeef, 0x0123456789abcdef, 1<<64 - 1,
+	} {
+		enc64 := EncodeUint64(x)
+		if want := fmt.Sprintf("%016x", x); string(enc64[:]) != want {
+			t.Errorf("EncodeUint64(%#x): expected %q, got %q", x, want, enc64)
+		}
+		dec64, err := DecodeUint64(string(enc64[:]))
+		if err != nil || dec64 != x {
+			t.Errorf("DecodeUint64(%q): got (%#x, %v)", enc64, dec64, err)
+		}
+
+		enc32 := EncodeUint32(uint32(x))
+		if want := fmt.Sprintf("%08x", uint32(x)); string(enc32[:]) != want {
+			t.Errorf("EncodeUint32(%#x): expected %q, got %q", uint32(x), want, enc32)
+		}
+		dec32, err := DecodeUint32(string(enc32[:]))
+		if err != nil || dec32 != uint32(x) {
+			t.Errorf("DecodeUint32(%q): got (%#x, %v)", enc32, dec32, err)
+		}
+	}
+
+	if x, err := DecodeUint64("DEADBEEFDEADBEEF"); err != nil || x != 0xdeadbeefdeadbeef {
+		t.Errorf("DecodeUint64: got (%#x, %v)", x, err)
+	}
+	for _, tt := range []struct {
+		in  string
+		err error
+	}{
+		{"", ErrWrongLength},
+		{"deadbeef", ErrWrongLength},
+		{"00000000deadbeef0", ErrWrongLength},
+		{"00000000deadbeeg", InvalidByteError('g')},
+	} {
+		x, err := DecodeUint64(tt.in)
+		if x != 0 || err != tt.err {
+			t.Errorf("DecodeUint64(%q): expected (0, %v), got (%#x, %v)", tt.in, tt.err, x, err)
+		}
+	}
+	if x, err := DecodeUint32("deadbeeg"); x != 0 || err != InvalidByteError('g') {
+		t.Errorf("DecodeUint32: got (%#x, %v)", x, err)
+	}
+}
+
+func TestUnsafeBytes(t *testing.T) {
+	for _, s := range []string{"", "a", "hello, world"} {
+		if b := unsafeBytes(s); !bytes.Equal(b, []byte(s)) || len(b) != len(s) {
+			t.Fatalf("expected %q, got %q", s, b)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package hex
+
+// GroupedEncodedLen returns the length of a grouped encoding of
+// n source bytes with group bytes per group.
+//
+// It panics if group <= 0.
+func GroupedEncodedLen(n, group int) int {
+	if group <= 0 {
+		panic("hex: invalid group size")
+	}
+	if n == 0 {
+		return 0
+	}
+	return EncodedLen(n) + (n-1)/group
+}
+
+// EncodeGrouped encodes src into GroupedEncodedLen(len(src),
+// group) bytes of dst, inserting sep between each group of
+// group source bytes.
+//
+// For example, encoding "\xaa\xbb\xcc\xdd" with a group size of
+// two and ':' as the separator produces "aabb:ccdd", and with
+// a group size of one and ' ' as the separator produces
+// "aa bb cc dd". This is commonly needed for MAC addresses, key
+// fingerprints, and serial numbers.
+//
+// As a convenience, it returns the number of bytes written to
+// dst, but this value is always GroupedEncodedLen(len(src),
+// group).
+//
+// EncodeGrouped runs in constant time for the length of src.
+func EncodeGrouped(dst, src []byte, group int, sep byte) int {
+	return encodeGrouped(dst, src, group, sep, lowerAlpha)
+}
+
+// encodeGrouped implements EncodeGrouped.
+//
+// alpha is either lowerAlpha or upperAlpha.
+func encodeGrouped(dst, src []byte, group int, sep byte, alpha uint) int {
+	n := GroupedEncodedLen(len(src), group)
+	j := 0
+	for len(src) > 0 {
+		m := group
+		if m > len(src) {
+			m = len(src)
+		}
+		j += encode(dst[j:], src[:m], alpha)
+		src = src[m:]
+		if len(src) > 0 {
+			dst[j] = sep
+			j++
+		}
+	}
+	return n
+}
+
+// EncodeToStringGrouped returns the grouped hexadecimal encoding
+// of src.
+//
+// See EncodeGrouped for more information.
+//
+// EncodeToStringGrouped runs in constant time for the length of
+// src.
+func EncodeToStringGrouped(src []byte, group int, sep byte) string {
+	dst := make([]byte, GroupedEncodedLen(len(src), group))
+	EncodeGrouped(dst, src, group, sep)
+	return string(dst)
+}
@@ -0,0 +1,46 @@
+package hex
+
+import "testing"
+
+func TestEncodeGrouped(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		group int
+		sep   byte
+		out   string
+	}{
+		{"", 1, ':', ""},
+		{"\xaa", 1, ':', "aa"},
+		{"\xaa\xbb\xcc\xdd", 1, ' ', "aa bb cc dd"},
+		{"\xaa\xbb\xcc\xdd", 2, ':', "aabb:ccdd"},
+		{"\xaa\xbb\xcc\xdd\xee", 2, '-', "aabb-ccdd-ee"},
+		{"\xaa\xbb\xcc\xdd", 4, ':', "aabbccdd"},
+		{"\xaa\xbb\xcc\xdd", 8, ':', "aabbccdd"},
+		{"\x00\x1a\x2b\x3c\x4d\x5e", 1, ':', "00:1a:2b:3c:4d:5e"},
+	} {
+		if n := GroupedEncodedLen(len(tt.in), tt.group); n != len(tt.out) {
+			t.Errorf("GroupedEncodedLen(%d, %d): expected %d, got %d",
+				len(tt.in), tt.group, len(tt.out), n)
+		}
+		dst := make([]byte, len(tt.out))
+		if n := EncodeGrouped(dst, []byte(tt.in), tt.group, tt.sep); n != len(tt.out) {
+			t.Errorf("EncodeGrouped(%q): bad return value: got: %d want: %d", tt.in, n, len(tt.out))
+		}
+		if string(dst) != tt.out {
+			t.Errorf("EncodeGrouped(%q): got: %q want: %q", tt.in, dst, tt.out)
+		}
+		if s := EncodeToStringGrouped([]byte(tt.in), tt.group, tt.sep); s != tt.out {
+			t.Errorf("EncodeToStringGrouped(%q): got: %q want: %q", tt.in, s, tt.out)
+		}
+	}
+}
+
+func TestEncodeGroupedRoundTrip(t *testing.T) {
+	src := []byte{0xde, 0xad, 0xbe, 0xef, 0x01}
+	enc := EncodeToStringGrouped(src, 2, ':')
+	dst := make([]byte, len(src))
+	n, err := DecodeFingerprint(dst, []byte(enc))
+	if err != nil || string(dst[:n]) != string(src) {
+		t.Fatalf("DecodeFingerprint(%q) = (%x, %v)", enc, dst[:n], err)
+	}
+}
@@ -6,20 +6,82 @@ package hex
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
+
+	"github.com/ericlagergren/subtle"
 )
 
-var ErrLength = hex.ErrLength
+// ErrLength results from decoding an odd length slice.
+//
+// errors.Is(ErrLength, hex.ErrLength) reports true for
+// compatibility with encoding/hex.
+var ErrLength error = lengthError{}
+
+type lengthError struct{}
+
+func (lengthError) Error() string {
+	return "hex: odd length hex string"
+}
+
+func (lengthError) Is(target error) bool {
+	return target == hex.ErrLength
+}
+
+// ErrCorrupt is returned by DecodeStrict and Bytes.UnmarshalText
+// for any malformed input.
+var ErrCorrupt = errors.New("hex: invalid input")
+
+// InvalidByteError values describe errors resulting from an
+// invalid byte in a hexadecimal string.
+//
+// For compatibility with encoding/hex, errors.Is reports true
+// for the encoding/hex.InvalidByteError with the same value and
+// errors.As can convert it to an encoding/hex.InvalidByteError.
+type InvalidByteError byte
+
+func (e InvalidByteError) Error() string {
+	return fmt.Sprintf("hex: invalid byte: %#U", rune(e))
+}
+
+// Is reports whether target is an encoding/hex.InvalidByteError
+// with the same value as e.
+func (e InvalidByteError) Is(target error) bool {
+	t, ok := target.(hex.InvalidByteError)
+	return ok && byte(t) == byte(e)
+}
 
-type InvalidByteError = hex.InvalidByteError
+// As sets target to e if target is a pointer to an
+// encoding/hex.InvalidByteError.
+func (e InvalidByteError) As(target interface{}) bool {
+	t, ok := target.(*hex.InvalidByteError)
+	if ok {
+		*t = hex.InvalidByteError(e)
+	}
+	return ok
+}
 
-// bufferSize is the number of hexadecimal characters to buffer
-// in encoder and decoder.
+// bufferSize is the default number of hexadecimal characters to
+// buffer in encoder and decoder.
 //
 // It's taken from encoding/hex and seemingly completely
 // arbitrary.
 const bufferSize = 1024
 
+// minBufferSize is the smallest buffer accepted by NewEncoderSize
+// and NewDecoderSize.
+const minBufferSize = 16
+
+// bufSize rounds size down to an even number no smaller than
+// minBufferSize.
+func bufSize(size int) int {
+	if size < minBufferSize {
+		return minBufferSize
+	}
+	return size &^ 1
+}
+
 // EncodedLen returns the length of an encoding of n source
 // bytes.
 // Specifically, it returns n * 2.
@@ -27,6 +89,16 @@ func EncodedLen(n int) int {
 	return hex.EncodedLen(n)
 }
 
+// AppendEncode appends the hexadecimal encoding of src to dst
+// and returns the extended buffer.
+//
+// AppendEncode runs in constant time for the length of src.
+func AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, EncodedLen(len(src)))
+	Encode(out, src)
+	return ret
+}
+
 // EncodeToString returns the hexadecimal encoding of src.
 //
 // Encode runs in constant time for the length of src.
@@ -36,27 +108,65 @@ func EncodeToString(src []byte) string {
 	return string(dst)
 }
 
-type encoder struct {
+// EncodeToStringUpper returns the uppercase hexadecimal
+// encoding of src.
+//
+// EncodeToStringUpper runs in constant time for the length of
+// src.
+func EncodeToStringUpper(src []byte) string {
+	dst := make([]byte, EncodedLen(len(src)))
+	EncodeUpper(dst, src)
+	return string(dst)
+}
+
+// Encoder writes hexadecimal characters to an underlying
+// io.Writer.
+type Encoder struct {
 	w   io.Writer
 	err error
-	out [bufferSize]byte // output buffer
+	out []byte // output buffer
 }
 
-// NewEncoder returns an io.Writer that writes lowercase
+var errEncoderClosed = errors.New("hex: encoder closed")
+
+// NewEncoder returns an io.WriteCloser that writes lowercase
 // hexadecimal characters to w.
-func NewEncoder(w io.Writer) io.Writer {
-	return &encoder{w: w}
+//
+// Closing the encoder wipes its internal buffer, which holds
+// encoded data. It does not close w.
+//
+// The result is an *Encoder. NewEncoderSize returns one
+// directly.
+func NewEncoder(w io.Writer) io.WriteCloser {
+	return NewEncoderSize(w, bufferSize)
 }
 
-func (e *encoder) Write(p []byte) (n int, err error) {
+// NewEncoderSize is like NewEncoder, but returns an *Encoder
+// that buffers up to size hexadecimal characters before writing
+// to w.
+//
+// size is rounded down to an even number. If size is less than
+// 16, 16 is used instead.
+func NewEncoderSize(w io.Writer, size int) *Encoder {
+	return &Encoder{w: w, out: make([]byte, bufSize(size))}
+}
+
+var (
+	_ io.WriteCloser  = (*Encoder)(nil)
+	_ io.ReaderFrom   = (*Encoder)(nil)
+	_ io.StringWriter = (*Encoder)(nil)
+)
+
+// Write implements io.Writer.
+func (e *Encoder) Write(p []byte) (n int, err error) {
 	for len(p) > 0 && e.err == nil {
-		chunkSize := bufferSize / 2
+		chunkSize := len(e.out) / 2
 		if len(p) < chunkSize {
 			chunkSize = len(p)
 		}
 
 		var written int
-		encoded := Encode(e.out[:], p[:chunkSize])
+		encoded := Encode(e.out, p[:chunkSize])
 		written, e.err = e.w.Write(e.out[:encoded])
 		n += written / 2
 		p = p[chunkSize:]
@@ -64,10 +174,80 @@ func (e *encoder) Write(p []byte) (n int, err error) {
 	return n, e.err
 }
 
+// WriteString is like Write, but does not copy s.
+func (e *Encoder) WriteString(s string) (n int, err error) {
+	return e.Write(unsafeBytes(s))
+}
+
+// ReadFrom encodes data from r until EOF and writes it to the
+// underlying io.Writer.
+//
+// ReadFrom reads directly into the back half of the encoder's
+// buffer and encodes in place, so io.Copy does not need an
+// intermediate buffer.
+func (e *Encoder) ReadFrom(r io.Reader) (n int64, err error) {
+	half := len(e.out) / 2
+	for e.err == nil {
+		m, rerr := r.Read(e.out[half:])
+		n += int64(m)
+		if m > 0 {
+			encoded := Encode(e.out, e.out[half:half+m])
+			var written int
+			written, e.err = e.w.Write(e.out[:encoded])
+			if e.err == nil && written != encoded {
+				e.err = io.ErrShortWrite
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+	return n, e.err
+}
+
+// Close wipes the encoder's internal buffer. Subsequent calls to
+// Write return an error.
+//
+// Close does not close the underlying io.Writer.
+func (e *Encoder) Close() error {
+	subtle.Wipe(e.out)
+	if e.err == nil {
+		e.err = errEncoderClosed
+	}
+	return nil
+}
+
+// Reset wipes the encoder's internal buffer, discards any error,
+// and switches the encoder to write to w.
+//
+// Reset allows an Encoder to be reused, including after Close,
+// without reallocating its buffer.
+func (e *Encoder) Reset(w io.Writer) {
+	subtle.Wipe(e.out)
+	e.w = w
+	e.err = nil
+}
+
 func DecodedLen(n int) int {
 	return hex.DecodedLen(n)
 }
 
+// AppendDecode appends the bytes represented by the hexadecimal
+// src to dst and returns the extended buffer.
+//
+// If the input is malformed, AppendDecode returns dst extended
+// by the bytes decoded before the error.
+//
+// AppendDecode runs in constant time for the length of src.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, DecodedLen(len(src)))
+	n, err := Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
 // DecodeString returns the bytes represented by the hexadecimal
 // string s.
 //
@@ -83,7 +263,52 @@ func DecodeString(s string) ([]byte, error) {
 	return src[:n], err
 }
 
-// NewDecoder returns an io.Reader that decodes hexadecimal
+// MustDecodeString is like DecodeString, but panics if s is
+// malformed.
+//
+// It is intended for decoding fixed keys and test vectors.
+func MustDecodeString(s string) []byte {
+	b, err := DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// DecodeStringInto decodes the hexadecimal string s into
+// DecodedLen(len(s)) bytes of dst, returning the actual number of
+// bytes written to dst.
+//
+// Unlike DecodeString, it does not allocate, so decoded secrets
+// can be written directly to caller-controlled (for example,
+// locked or stack-allocated) memory. Like Decode, if the input
+// is malformed, DecodeStringInto returns the number of bytes
+// decoded before the error.
+//
+// DecodeStringInto runs in constant time for the length of s.
+func DecodeStringInto(dst []byte, s string) (int, error) {
+	return Decode(dst, unsafeBytes(s))
+}
+
+// DecodeInPlace decodes the hexadecimal characters in buf over
+// themselves, returning the number of bytes written to buf.
+//
+// The decoded bytes are written to buf[:n]. The rest of buf,
+// which would otherwise still hold the encoded form of the
+// second half of the decoded bytes, is wiped. This allows
+// secrets to be decoded without a second buffer.
+//
+// Like Decode, if the input is malformed, DecodeInPlace returns
+// the number of bytes decoded before the error.
+//
+// DecodeInPlace runs in constant time for the length of buf.
+func DecodeInPlace(buf []byte) (int, error) {
+	n, err := Decode(buf, buf)
+	subtle.Wipe(buf[n:])
+	return n, err
+}
+
+// NewDecoder returns an io.ReadCloser that decodes hexadecimal
 // characters from r.
 //
 // NewDecoder expects that r contain only an even number of
@@ -92,26 +317,67 @@ func DecodeString(s string) ([]byte, error) {
 // The first call to Read that encounters malformed hexadecimal
 // characters will return a non-nil error. This means that the
 // io.Reader does not operate in constant time over the entire
-// stream, but rather for each chunk read from r.
-func NewDecoder(r io.Reader) io.Reader {
-	return &decoder{r: r}
+// stream, but rather for each chunk read from r. See
+// NewBufferedDecoder for a decoder that does.
+//
+// Closing the decoder wipes its internal buffer, which holds
+// encoded data. It does not close r.
+//
+// The result is a *Decoder. NewDecoderSize returns one
+// directly.
+func NewDecoder(r io.Reader) io.ReadCloser {
+	return NewDecoderSize(r, bufferSize)
+}
+
+// NewDecoderSize is like NewDecoder, but returns a *Decoder
+// that reads up to size hexadecimal characters from r at
+// a time.
+//
+// size is rounded down to an even number. If size is less than
+// 16, 16 is used instead.
+func NewDecoderSize(r io.Reader, size int) *Decoder {
+	return &Decoder{r: r, arr: make([]byte, bufSize(size))}
 }
 
-type decoder struct {
+// Decoder decodes hexadecimal characters from an underlying
+// io.Reader.
+type Decoder struct {
 	r   io.Reader
 	err error
-	in  []byte           // input buffer (encoded form)
-	arr [bufferSize]byte // backing array for in
+	in  []byte // input buffer (encoded form)
+	arr []byte // backing array for in
+
+	limited bool // whether max applies
+	max     int  // maximum number of characters to read
+	left    int  // characters left before max is reached
 }
 
-var _ io.Reader = (*decoder)(nil)
+var (
+	_ io.ReadCloser = (*Decoder)(nil)
+	_ io.WriterTo   = (*Decoder)(nil)
+)
 
-func (d *decoder) Read(p []byte) (n int, err error) {
-	// Fill internal buffer with sufficient bytes to decode
+var errDecoderClosed = errors.New("hex: decoder closed")
+
+// fill refills the internal buffer if it holds fewer than two
+// characters.
+func (d *Decoder) fill() {
 	if len(d.in) < 2 && d.err == nil {
 		var numCopy, numRead int
-		numCopy = copy(d.arr[:], d.in) // Copies either 0 or 1 bytes
-		numRead, d.err = d.r.Read(d.arr[numCopy:])
+		numCopy = copy(d.arr, d.in) // Copies either 0 or 1 bytes
+		buf := d.arr[numCopy:]
+		if d.limited && len(buf) > d.left {
+			// Read one extra character to detect input that is
+			// too large.
+			buf = buf[:d.left+1]
+		}
+		numRead, d.err = d.r.Read(buf)
+		if d.limited {
+			if numRead > d.left {
+				numRead, d.err = d.left, ErrTooLarge
+			}
+			d.left -= numRead
+		}
 		d.in = d.arr[:numCopy+numRead]
 		if d.err == io.EOF && len(d.in)%2 != 0 {
 			if !validHexChar(d.in[len(d.in)-1]) {
@@ -121,6 +387,27 @@ func (d *decoder) Read(p []byte) (n int, err error) {
 			}
 		}
 	}
+}
+
+// NewDecoderLimit is like NewDecoder, but decodes at most max
+// bytes.
+//
+// Once r has produced EncodedLen(max) characters, the decoder
+// reads at most one more character from r. If r has more data,
+// Read and WriteTo return ErrTooLarge after returning the first
+// max bytes.
+func NewDecoderLimit(r io.Reader, max int) *Decoder {
+	d := NewDecoderSize(r, bufferSize)
+	d.limited = true
+	d.max = EncodedLen(max)
+	d.left = d.max
+	return d
+}
+
+// Read implements io.Reader.
+func (d *Decoder) Read(p []byte) (n int, err error) {
+	// Fill internal buffer with sufficient bytes to decode
+	d.fill()
 
 	// Decode internal buffer into output buffer
 	if numAvail := len(d.in) / 2; len(p) > numAvail {
@@ -137,3 +424,66 @@ func (d *decoder) Read(p []byte) (n int, err error) {
 	}
 	return numDec, nil
 }
+
+// WriteTo decodes data from the underlying io.Reader until EOF
+// and writes it to w.
+//
+// WriteTo decodes in place in the decoder's internal buffer, so
+// io.Copy does not need an intermediate buffer.
+func (d *Decoder) WriteTo(w io.Writer) (n int64, err error) {
+	for {
+		d.fill()
+
+		// Decode in place: the decoded bytes overwrite the
+		// first half of the characters they were decoded from.
+		numDec, err := Decode(d.in, d.in[:len(d.in)/2*2])
+		out := d.in[:numDec]
+		d.in = d.in[2*numDec:]
+		if err != nil {
+			d.in, d.err = nil, err // Decode error; discard input remainder
+		}
+
+		if numDec > 0 {
+			written, werr := w.Write(out)
+			n += int64(written)
+			if werr == nil && written != numDec {
+				werr = io.ErrShortWrite
+			}
+			if werr != nil {
+				return n, werr
+			}
+		}
+
+		if len(d.in) < 2 && d.err != nil {
+			if d.err == io.EOF {
+				return n, nil
+			}
+			return n, d.err
+		}
+	}
+}
+
+// Close wipes the decoder's internal buffer and discards any
+// buffered input. Subsequent calls to Read return an error.
+//
+// Close does not close the underlying io.Reader.
+func (d *Decoder) Close() error {
+	subtle.Wipe(d.arr)
+	d.in = nil
+	d.err = errDecoderClosed
+	return nil
+}
+
+// Reset wipes the decoder's internal buffer, discards any
+// buffered input and error, and switches the decoder to read
+// from r.
+//
+// Reset allows a Decoder to be reused, including after Close,
+// without reallocating its buffer.
+func (d *Decoder) Reset(r io.Reader) {
+	subtle.Wipe(d.arr)
+	d.r = r
+	d.in = nil
+	d.err = nil
+	d.left = d.max
+}
@@ -6,10 +6,13 @@ package hex
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -221,3 +224,578 @@ func BenchmarkDecode(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkDecodeStrict compares the time taken to decode valid
+// input against input with an error at either end. The times
+// should be the same.
+func BenchmarkDecodeStrict(b *testing.B) {
+	const size = 8192
+	valid := bytes.Repeat([]byte{'2', 'b', '7', '4', '4', 'f', 'a', 'a'}, size/8)
+	first := append([]byte(nil), valid...)
+	first[0] = 'z'
+	last := append([]byte(nil), valid...)
+	last[len(last)-1] = 'z'
+	sink = make([]byte, size/2)
+
+	for _, tc := range []struct {
+		name string
+		src  []byte
+	}{
+		{"valid", valid},
+		{"invalid-first", first},
+		{"invalid-last", last},
+	} {
+		tc := tc
+		b.Run(tc.name, func(b *testing.B) {
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				DecodeStrict(sink, tc.src)
+			}
+		})
+	}
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func TestEncodeReversed(t *testing.T) {
+	for i, test := range encDecTests {
+		dst := make([]byte, EncodedLen(len(test.dec)))
+		n := EncodeReversed(dst, test.dec)
+		if n != len(dst) {
+			t.Errorf("#%d: bad return value: got: %d want: %d", i, n, len(dst))
+		}
+		want := hex.EncodeToString(reverse(test.dec))
+		if string(dst) != want {
+			t.Errorf("#%d: got: %q want: %q", i, dst, want)
+		}
+	}
+}
+
+func TestDecodeReversed(t *testing.T) {
+	for i, test := range encDecTests {
+		src := []byte(hex.EncodeToString(reverse(test.dec)))
+		dst := make([]byte, DecodedLen(len(src)))
+		n, err := DecodeReversed(dst, src)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if n != len(dst) {
+			t.Errorf("#%d: bad return value: got: %d want: %d", i, n, len(dst))
+		} else if !bytes.Equal(dst, test.dec) {
+			t.Errorf("#%d: got: %#v want: %#v", i, dst, test.dec)
+		}
+	}
+	for _, tt := range errTests {
+		out := make([]byte, len(tt.in)/2)
+		n, err := DecodeReversed(out, []byte(tt.in))
+		got := string(reverse(out[len(out)-n:]))
+		if got != tt.out || err != tt.err {
+			t.Errorf("DecodeReversed(%q) = (%d, %q, %v), want (%d, %q, %v)",
+				tt.in, n, got, err, len(tt.out), tt.out, tt.err)
+		}
+	}
+}
+
+func TestEncodeUpper(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		src := []byte{byte(i)}
+		dst := make([]byte, 2)
+		EncodeUpper(dst, src)
+		want := strings.ToUpper(hex.EncodeToString(src))
+		if string(dst) != want {
+			t.Errorf("#%d: got: %q want: %q", i, dst, want)
+		}
+	}
+	for i, test := range encDecTests {
+		s := EncodeToStringUpper(test.dec)
+		if want := strings.ToUpper(test.enc); s != want {
+			t.Errorf("#%d got:%s want:%s", i, s, want)
+		}
+	}
+}
+
+func TestAppendEncode(t *testing.T) {
+	for i, test := range encDecTests {
+		prefix := []byte("prefix:")
+		got := AppendEncode(prefix[:len(prefix):len(prefix)], test.dec)
+		if want := "prefix:" + test.enc; string(got) != want {
+			t.Errorf("#%d: got: %q want: %q", i, got, want)
+		}
+		buf := make([]byte, 0, 64)
+		got = AppendEncode(buf, test.dec)
+		if string(got) != test.enc {
+			t.Errorf("#%d: got: %q want: %q", i, got, test.enc)
+		}
+	}
+}
+
+func TestAppendDecode(t *testing.T) {
+	for i, test := range encDecTests {
+		prefix := []byte("prefix:")
+		got, err := AppendDecode(prefix[:len(prefix):len(prefix)], []byte(test.enc))
+		if err != nil {
+			t.Errorf("#%d: unexpected err value: %s", i, err)
+			continue
+		}
+		if want := append([]byte("prefix:"), test.dec...); !bytes.Equal(got, want) {
+			t.Errorf("#%d: got: %q want: %q", i, got, want)
+		}
+	}
+	for _, tt := range errTests {
+		got, err := AppendDecode([]byte("x"), []byte(tt.in))
+		if string(got) != "x"+tt.out || err != tt.err {
+			t.Errorf("AppendDecode(%q) = (%q, %v), want (%q, %v)", tt.in, got, err, "x"+tt.out, tt.err)
+		}
+	}
+}
+
+func TestEncodeLengths(t *testing.T) {
+	src := make([]byte, 100)
+	for i := range src {
+		src[i] = byte(i * 37)
+	}
+	for n := 0; n <= len(src); n++ {
+		dst := make([]byte, EncodedLen(n))
+		Encode(dst, src[:n])
+		want := hex.EncodeToString(src[:n])
+		if string(dst) != want {
+			t.Fatalf("%d: got: %q want: %q", n, dst, want)
+		}
+		EncodeUpper(dst, src[:n])
+		if want := strings.ToUpper(want); string(dst) != want {
+			t.Fatalf("%d: got: %q want: %q", n, dst, want)
+		}
+	}
+}
+
+func TestEncodeSWARExhaustive(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		for j := 0; j < 256; j++ {
+			src := []byte{byte(i), byte(j), byte(i), byte(j), 0, 0xff, byte(j), byte(i)}
+			dst := make([]byte, EncodedLen(len(src)))
+			Encode(dst, src)
+			if want := hex.EncodeToString(src); string(dst) != want {
+				t.Fatalf("got: %q want: %q", dst, want)
+			}
+		}
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	for i, test := range encDecTests {
+		dst := make([]byte, DecodedLen(len(test.enc)))
+		n, err := DecodeStrict(dst, []byte(test.enc))
+		if err != nil || n != len(test.dec) || !bytes.Equal(dst, test.dec) {
+			t.Errorf("#%d: got: (%d, %x, %v) want: (%d, %x, nil)", i, n, dst, err, len(test.dec), test.dec)
+		}
+	}
+	for _, tt := range errTests {
+		dst := bytes.Repeat([]byte{0xaa}, DecodedLen(len(tt.in)))
+		n, err := DecodeStrict(dst, []byte(tt.in))
+		wantErr := ErrCorrupt
+		if tt.err == nil {
+			wantErr = nil
+		}
+		if err != wantErr {
+			t.Errorf("DecodeStrict(%q): expected %v, got %v", tt.in, wantErr, err)
+		}
+		if err != nil {
+			if n != 0 {
+				t.Errorf("DecodeStrict(%q): expected 0 bytes, got %d", tt.in, n)
+			}
+			if !bytes.Equal(dst, make([]byte, len(dst))) {
+				t.Errorf("DecodeStrict(%q): dst not zeroed: %x", tt.in, dst)
+			}
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	for i, test := range encDecTests {
+		if !Valid([]byte(test.enc)) || !ValidString(test.enc) {
+			t.Errorf("#%d: %q should be valid", i, test.enc)
+		}
+	}
+	if !ValidString("F8F9FAFBFCFDFEFF") {
+		t.Error("uppercase input should be valid")
+	}
+	for _, tt := range errTests {
+		want := tt.err == nil
+		if got := ValidString(tt.in); got != want {
+			t.Errorf("ValidString(%q): expected %t, got %t", tt.in, want, got)
+		}
+	}
+	for i := 0; i < 256; i++ {
+		s := string([]byte{'a', byte(i)})
+		_, err := hex.DecodeString(s)
+		if want := err == nil; Valid([]byte(s)) != want {
+			t.Errorf("Valid(%q): expected %t", s, want)
+		}
+	}
+}
+
+func TestMustDecodeString(t *testing.T) {
+	for i, test := range encDecTests {
+		if got := MustDecodeString(test.enc); !bytes.Equal(got, test.dec) {
+			t.Errorf("#%d: got: %#v want: #%v", i, got, test.dec)
+		}
+	}
+	for _, tt := range errTests {
+		if tt.err == nil {
+			continue
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != tt.err {
+					t.Errorf("MustDecodeString(%q): expected panic(%v), got %v", tt.in, tt.err, r)
+				}
+			}()
+			MustDecodeString(tt.in)
+		}()
+	}
+}
+
+func TestDecodeStringInto(t *testing.T) {
+	for i, test := range encDecTests {
+		var arr [16]byte
+		n, err := DecodeStringInto(arr[:], test.enc)
+		if err != nil {
+			t.Errorf("#%d: unexpected err value: %s", i, err)
+			continue
+		}
+		if !bytes.Equal(arr[:n], test.dec) {
+			t.Errorf("#%d: got: %#v want: #%v", i, arr[:n], test.dec)
+		}
+	}
+	for _, tt := range errTests {
+		out := make([]byte, len(tt.in))
+		n, err := DecodeStringInto(out, tt.in)
+		if string(out[:n]) != tt.out || err != tt.err {
+			t.Errorf("DecodeStringInto(%q) = (%q, %v), want (%q, %v)", tt.in, out[:n], err, tt.out, tt.err)
+		}
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		var arr [32]byte
+		DecodeStringInto(arr[:], "0001020304050607")
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestEncoderDecoderCopy(t *testing.T) {
+	for _, multiplier := range []int{1, 128, 192, 1000} {
+		for _, test := range encDecTests {
+			input := bytes.Repeat(test.dec, multiplier)
+			output := strings.Repeat(test.enc, multiplier)
+
+			// io.Copy uses encoder.ReadFrom and decoder.WriteTo.
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf)
+			r := struct{ io.Reader }{bytes.NewReader(input)} // io.Reader only; not io.WriterTo
+			if n, err := io.Copy(enc, r); n != int64(len(input)) || err != nil {
+				t.Errorf("encoder.ReadFrom(%q*%d) = (%d, %v), want (%d, nil)", test.dec, multiplier, n, err, len(input))
+				continue
+			}
+			if encDst := buf.String(); encDst != output {
+				t.Errorf("buf(%q*%d) = %v, want %v", test.dec, multiplier, encDst, output)
+				continue
+			}
+
+			dec := NewDecoder(struct{ io.Reader }{iotest.HalfReader(&buf)})
+			var decBuf bytes.Buffer
+			w := struct{ io.Writer }{&decBuf} // io.Writer only; not io.ReaderFrom
+			if n, err := io.Copy(w, dec); n != int64(len(input)) || err != nil {
+				t.Errorf("decoder.WriteTo(%q*%d) = (%d, %v), want (%d, nil)", test.enc, multiplier, n, err, len(input))
+			}
+			if !bytes.Equal(decBuf.Bytes(), input) {
+				t.Errorf("decBuf(%q*%d) = %v, want %v", test.dec, multiplier, decBuf.Bytes(), input)
+			}
+		}
+	}
+}
+
+func TestDecoderWriteToErr(t *testing.T) {
+	for _, tt := range errTests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in))).(io.WriterTo)
+		var buf bytes.Buffer
+		_, err := dec.WriteTo(&buf)
+		wantErr := tt.err
+		if wantErr == ErrLength {
+			wantErr = io.ErrUnexpectedEOF
+		}
+		if buf.String() != tt.out || err != wantErr {
+			t.Errorf("WriteTo(%q) = (%q, %v), want (%q, %v)", tt.in, buf.String(), err, tt.out, wantErr)
+		}
+	}
+}
+
+func TestEncoderWriteString(t *testing.T) {
+	for _, test := range encDecTests {
+		input := strings.Repeat(string(test.dec), 700)
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		n, err := io.WriteString(enc, input)
+		if n != len(input) || err != nil {
+			t.Errorf("WriteString(%q*700) = (%d, %v), want (%d, nil)", test.dec, n, err, len(input))
+			continue
+		}
+		if want := strings.Repeat(test.enc, 700); buf.String() != want {
+			t.Errorf("buf(%q*700) = %q, want %q", test.dec, buf.String(), want)
+		}
+	}
+	enc := NewEncoder(io.Discard)
+	allocs := testing.AllocsPerRun(100, func() {
+		io.WriteString(enc, "hello, world")
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestEncoderDecoderSize(t *testing.T) {
+	input := make([]byte, 5000)
+	for i := range input {
+		input[i] = byte(i * 13)
+	}
+	output := hex.EncodeToString(input)
+	for _, size := range []int{-1, 0, 1, 16, 17, 100, 4096} {
+		var buf bytes.Buffer
+		enc := NewEncoderSize(&buf, size)
+		if n := len(enc.out); n != bufSize(size) || n%2 != 0 || n < minBufferSize {
+			t.Errorf("NewEncoderSize(%d): unexpected buffer size %d", size, n)
+		}
+		if _, err := io.CopyBuffer(enc, struct{ io.Reader }{bytes.NewReader(input)}, make([]byte, 7)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != output {
+			t.Fatalf("NewEncoderSize(%d): incorrect output", size)
+		}
+
+		dec := NewDecoderSize(&buf, size)
+		if n := len(dec.arr); n != bufSize(size) {
+			t.Errorf("NewDecoderSize(%d): unexpected buffer size %d", size, n)
+		}
+		got, err := io.ReadAll(dec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, input) {
+			t.Fatalf("NewDecoderSize(%d): incorrect output", size)
+		}
+	}
+}
+
+func TestEncoderClose(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if _, err := enc.Write([]byte{0xde, 0xad}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "dead" {
+		t.Fatalf("expected %q, got %q", "dead", buf.String())
+	}
+	e := enc.(*Encoder)
+	if !bytes.Equal(e.out, make([]byte, bufferSize)) {
+		t.Fatal("Close did not wipe the buffer")
+	}
+	if _, err := enc.Write([]byte{0xbe}); err == nil {
+		t.Fatal("expected an error after Close")
+	}
+}
+
+func TestDecoderClose(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("deadbeef"))
+	var p [1]byte
+	if n, err := dec.Read(p[:]); n != 1 || err != nil || p[0] != 0xde {
+		t.Fatalf("Read = (%d, %v, %#x)", n, err, p[0])
+	}
+	if err := dec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d := dec.(*Decoder)
+	if !bytes.Equal(d.arr, make([]byte, bufferSize)) {
+		t.Fatal("Close did not wipe the buffer")
+	}
+	if _, err := dec.Read(p[:]); err == nil {
+		t.Fatal("expected an error after Close")
+	}
+}
+
+func TestDecodeInPlace(t *testing.T) {
+	for i, test := range encDecTests {
+		buf := []byte(test.enc)
+		n, err := DecodeInPlace(buf)
+		if err != nil {
+			t.Errorf("#%d: unexpected err value: %s", i, err)
+			continue
+		}
+		if !bytes.Equal(buf[:n], test.dec) {
+			t.Errorf("#%d: got: %#v want: #%v", i, buf[:n], test.dec)
+		}
+		if !bytes.Equal(buf[n:], make([]byte, len(buf)-n)) {
+			t.Errorf("#%d: remainder not wiped: %#v", i, buf[n:])
+		}
+	}
+	for _, tt := range errTests {
+		buf := []byte(tt.in)
+		n, err := DecodeInPlace(buf)
+		if string(buf[:n]) != tt.out || err != tt.err {
+			t.Errorf("DecodeInPlace(%q) = (%q, %v), want (%q, %v)", tt.in, buf[:n], err, tt.out, tt.err)
+		}
+	}
+}
+
+func TestEqualEncoded(t *testing.T) {
+	for _, tt := range []struct {
+		encoded string
+		raw     string
+		want    int
+	}{
+		{"", "", 1},
+		{"deadbeef", "\xde\xad\xbe\xef", 1},
+		{"DEADbeef", "\xde\xad\xbe\xef", 1},
+		{"deadbeee", "\xde\xad\xbe\xef", 0},
+		{"deadbeef", "\xde\xad\xbe", 0},
+		{"deadbee", "\xde\xad\xbe\xef", 0},
+		{"deadbeefg", "\xde\xad\xbe\xef", 0},
+		{"deadbeeg", "\xde\xad\xbe\xe0", 0},
+		{"0g", "\x00", 0},
+		{"  ", "\x00", 0},
+	} {
+		got := EqualEncoded([]byte(tt.encoded), []byte(tt.raw))
+		if got != tt.want {
+			t.Errorf("EqualEncoded(%q, %x): expected %d, got %d",
+				tt.encoded, tt.raw, tt.want, got)
+		}
+	}
+	for b := 0; b < 256; b++ {
+		raw := []byte{byte(b)}
+		for _, enc := range []string{EncodeToString(raw), EncodeToStringUpper(raw)} {
+			if EqualEncoded([]byte(enc), raw) != 1 {
+				t.Errorf("EqualEncoded(%q, %x): expected 1", enc, raw)
+			}
+		}
+	}
+}
+
+func TestEncoderReset(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	enc := NewEncoder(&buf1).(*Encoder)
+	if _, err := enc.Write([]byte{0xde, 0xad}); err != nil {
+		t.Fatal(err)
+	}
+	enc.Close()
+	enc.Reset(&buf2)
+	if !bytes.Equal(enc.out, make([]byte, bufferSize)) {
+		t.Fatal("Reset did not wipe the buffer")
+	}
+	if _, err := enc.Write([]byte{0xbe, 0xef}); err != nil {
+		t.Fatal(err)
+	}
+	if buf1.String() != "dead" || buf2.String() != "beef" {
+		t.Fatalf("got %q and %q", buf1.String(), buf2.String())
+	}
+}
+
+func TestDecoderReset(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("deadzz")).(*Decoder)
+	if _, err := io.ReadAll(dec); err != InvalidByteError('z') {
+		t.Fatalf("expected %v, got %v", InvalidByteError('z'), err)
+	}
+	dec.Reset(strings.NewReader("beef"))
+	if !bytes.Equal(dec.arr, make([]byte, bufferSize)) {
+		t.Fatal("Reset did not wipe the buffer")
+	}
+	got, err := io.ReadAll(dec)
+	if err != nil || !bytes.Equal(got, []byte{0xbe, 0xef}) {
+		t.Fatalf("ReadAll = (%x, %v)", got, err)
+	}
+
+	// Reset discards buffered input.
+	dec = NewDecoder(strings.NewReader("deadbeef")).(*Decoder)
+	var p [1]byte
+	if _, err := dec.Read(p[:]); err != nil {
+		t.Fatal(err)
+	}
+	dec.Reset(strings.NewReader("0102"))
+	got, err = io.ReadAll(dec)
+	if err != nil || !bytes.Equal(got, []byte{1, 2}) {
+		t.Fatalf("ReadAll = (%x, %v)", got, err)
+	}
+}
+
+func TestDecoderLimit(t *testing.T) {
+	const max = 1000
+	for _, n := range []int{0, 1, max - 1, max, max + 1, 4 * max} {
+		input := strings.Repeat("ab", n)
+		for _, r := range []io.Reader{
+			strings.NewReader(input),
+			iotest.OneByteReader(strings.NewReader(input)),
+		} {
+			got, err := io.ReadAll(NewDecoderLimit(r, max))
+			want := n
+			var wantErr error
+			if n > max {
+				want, wantErr = max, ErrTooLarge
+			}
+			if len(got) != want || err != wantErr {
+				t.Errorf("%d: expected (%d, %v), got (%d, %v)", n, want, wantErr, len(got), err)
+			}
+		}
+	}
+
+	// Only one character past the limit is read.
+	r := strings.NewReader(strings.Repeat("ab", 100))
+	dec := NewDecoderLimit(r, 10)
+	var buf bytes.Buffer
+	if _, err := dec.WriteTo(&buf); err != ErrTooLarge || buf.Len() != 10 {
+		t.Fatalf("WriteTo = (%d, %v), want (10, %v)", buf.Len(), err, ErrTooLarge)
+	}
+	if r.Len() != 200-21 {
+		t.Fatalf("expected %d unread characters, got %d", 200-21, r.Len())
+	}
+
+	// Reset restores the limit.
+	dec.Reset(strings.NewReader(strings.Repeat("cd", 10)))
+	got, err := io.ReadAll(dec)
+	if err != nil || len(got) != 10 {
+		t.Fatalf("ReadAll = (%d, %v)", len(got), err)
+	}
+}
+
+func TestErrorCompat(t *testing.T) {
+	if !errors.Is(ErrLength, hex.ErrLength) {
+		t.Error("ErrLength is not hex.ErrLength")
+	}
+	if errors.Is(ErrLength, ErrCorrupt) {
+		t.Error("ErrLength is ErrCorrupt")
+	}
+	_, err := DecodeString("0")
+	if !errors.Is(err, hex.ErrLength) {
+		t.Errorf("%v is not hex.ErrLength", err)
+	}
+
+	_, err = DecodeString("zz")
+	if !errors.Is(err, hex.InvalidByteError('z')) {
+		t.Errorf("%v is not hex.InvalidByteError('z')", err)
+	}
+	if errors.Is(err, hex.InvalidByteError('y')) {
+		t.Errorf("%v is hex.InvalidByteError('y')", err)
+	}
+	var target hex.InvalidByteError
+	if !errors.As(err, &target) || target != 'z' {
+		t.Errorf("errors.As(%v): got %q", err, byte(target))
+	}
+	if want := "hex: invalid byte: U+007A 'z'"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+package hex
+
+import (
+	"errors"
+
+	"github.com/ericlagergren/subtle"
+)
+
+var errNoDigits = errors.New("hex: literal has no digits")
+
+// ParseLiteral decodes a Go-style hexadecimal literal like
+// "0xdead_beef".
+//
+// The "0x" or "0X" prefix is optional. Underscores may separate
+// digits, or follow the prefix, but may not lead, trail, or
+// repeat. An odd number of digits is padded on the left with
+// a zero, so "0xabc" decodes to {0x0a, 0xbc}.
+//
+// If s is malformed, ParseLiteral returns nil and an error. An
+// invalid digit is reported before a misplaced underscore, which
+// is reported as InvalidByteError('_').
+//
+// ParseLiteral runs in constant time for the length of s.
+// However, the presence of the prefix and the number of
+// underscores are revealed by the result.
+func ParseLiteral(s string) ([]byte, error) {
+	prefix := 0
+	if len(s) >= 2 {
+		prefix = subtle.ConstantTimeByteEq(s[0], '0') &
+			subtle.ConstantTimeByteEq(s[1]|0x20, 'x')
+	}
+	s = s[prefix*2:]
+
+	var arr [128]byte
+	buf := arr[:]
+	if len(s)+1 > len(arr) {
+		buf = make([]byte, len(s)+1)
+	}
+	defer subtle.Wipe(buf)
+
+	// Compact the digits into buf[1:], leaving room for
+	// a padding zero. Without a prefix, a leading underscore is
+	// treated like a repeated underscore.
+	j := 0
+	prev := prefix ^ 1
+	bad := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		u := subtle.ConstantTimeByteEq(c, '_')
+		bad |= u & prev
+		prev = u
+		buf[1+j] = c
+		j += u ^ 1
+	}
+	bad |= prev
+	if j == 0 {
+		return nil, errNoDigits
+	}
+
+	// Pad an odd number of digits with a leading zero.
+	buf[0] = '0'
+	off := (j & 1) ^ 1
+	out := make([]byte, (j+1)/2)
+	_, err := Decode(out, buf[off:1+j])
+	if err == nil && bad != 0 {
+		err = InvalidByteError('_')
+	}
+	if err != nil {
+		subtle.Wipe(out)
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package hex
+
+import "testing"
+
+func TestParseLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out string
+		err error
+	}{
+		{"0xdeadbeef", "\xde\xad\xbe\xef", nil},
+		{"0XDEAD_BEEF", "\xde\xad\xbe\xef", nil},
+		{"dead_beef", "\xde\xad\xbe\xef", nil},
+		{"0x_de_ad", "\xde\xad", nil},
+		{"d_e_a_d", "\xde\xad", nil},
+		{"0xabc", "\x0a\xbc", nil},
+		{"f", "\x0f", nil},
+		{"0", "\x00", nil},
+		{"00", "\x00", nil},
+		{"0x0", "\x00", nil},
+		{"x0", "", InvalidByteError('x')},
+
+		{"", "", errNoDigits},
+		{"0x", "", errNoDigits},
+		{"_", "", errNoDigits},
+		{"0x__", "", errNoDigits},
+		{"_dead", "", InvalidByteError('_')},
+		{"dead_", "", InvalidByteError('_')},
+		{"0x_", "", errNoDigits},
+		{"0xde__ad", "", InvalidByteError('_')},
+		{"0x__dead", "", InvalidByteError('_')},
+		{"0xdeag", "", InvalidByteError('g')},
+		{"0xde__ag", "", InvalidByteError('g')},
+		{"0x0x12", "", InvalidByteError('x')},
+	} {
+		got, err := ParseLiteral(tt.in)
+		if string(got) != tt.out || err != tt.err {
+			t.Errorf("ParseLiteral(%q) = (%q, %v), want (%q, %v)",
+				tt.in, got, err, tt.out, tt.err)
+		}
+		if err != nil && got != nil {
+			t.Errorf("ParseLiteral(%q): expected nil result on error", tt.in)
+		}
+	}
+}
+
+func TestParseLiteralLong(t *testing.T) {
+	src := make([]byte, 100)
+	for i := range src {
+		src[i] = byte(i * 7)
+	}
+	s := "0x" + EncodeToString(src)
+	got, err := ParseLiteral(s)
+	if err != nil || string(got) != string(src) {
+		t.Fatalf("ParseLiteral(%q) = (%x, %v)", s, got, err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package alphabet implements constant-time lookups for the
+// alphabets used by the codecs.
+//
+// Table lookups indexed by secret data leak through the cache.
+// Instead, an Alphabet decomposes its characters into runs of
+// consecutive characters with consecutive values, and each
+// lookup checks every run with arithmetic alone. Most
+// alphabets have only a handful of runs; RFC 4648 base32, for
+// example, has two: "A-Z" and "2-7".
+//
+// The time taken by a lookup depends on the number of runs, so
+// alphabets with fewer runs are faster. The alphabet itself is
+// not secret, so this reveals nothing about the data.
+package alphabet
+
+// span maps the characters [lo, lo+n) to the values [v, v+n).
+type span struct {
+	lo byte
+	n  byte
+	v  byte
+}
+
+// Alphabet maps values to characters and back in constant time.
+type Alphabet struct {
+	enc []span // value -> character
+	dec []span // character -> value, including aliases
+	set [256]bool
+}
+
+// New creates an Alphabet where chars[i] encodes the value i.
+//
+// New panics if chars is longer than 256 characters or contains
+// duplicates.
+func New(chars string) *Alphabet {
+	if len(chars) > 256 {
+		panic("alphabet: too many characters")
+	}
+	a := &Alphabet{}
+	for i := 0; i < len(chars); i++ {
+		a.enc = appendSpan(a.enc, chars[i], byte(i))
+		a.Alias(chars[i], byte(i))
+	}
+	return a
+}
+
+// Alias adds c as an additional character that decodes to v.
+// Encoding is unchanged.
+//
+// Alias panics if c is already part of the alphabet.
+func (a *Alphabet) Alias(c, v byte) {
+	if a.set[c] {
+		panic("alphabet: duplicate character")
+	}
+	a.set[c] = true
+	a.dec = appendSpan(a.dec, c, v)
+}
+
+// FoldCase adds the lowercase form of each uppercase letter
+// in the alphabet as an alias, and vice versa, so that decoding
+// is case insensitive.
+func (a *Alphabet) FoldCase() {
+	var folds [][2]byte
+	for _, s := range a.dec {
+		for i := byte(0); i < s.n; i++ {
+			c := s.lo + i
+			var f byte
+			switch {
+			case 'A' <= c && c <= 'Z':
+				f = c + 'a' - 'A'
+			case 'a' <= c && c <= 'z':
+				f = c - ('a' - 'A')
+			default:
+				continue
+			}
+			if !a.set[f] {
+				folds = append(folds, [2]byte{f, s.v + i})
+			}
+		}
+	}
+	for _, f := range folds {
+		a.Alias(f[0], f[1])
+	}
+}
+
+// Contains reports whether c is a character in the alphabet,
+// including aliases.
+//
+// Contains does not run in constant time. It is intended for
+// validating configuration, like padding characters, not data.
+func (a *Alphabet) Contains(c byte) bool {
+	return a.set[c]
+}
+
+// appendSpan appends the mapping c -> v to spans, extending the
+// last span if possible.
+func appendSpan(spans []span, c, v byte) []span {
+	if n := len(spans); n > 0 {
+		s := &spans[n-1]
+		if s.n < 255 && s.lo+s.n == c && s.v+s.n == v {
+			s.n++
+			return spans
+		}
+	}
+	return append(spans, span{lo: c, n: 1, v: v})
+}
+
+// inRange returns 0xff if lo <= x < lo+n and 0 otherwise.
+//
+// inRange runs in constant time.
+func inRange(x, lo, n byte) byte {
+	d := int(x) - int(lo)
+	// ge is 0xff if d >= 0; lt is 0xff if d < n.
+	ge := ^byte(d >> 8)
+	lt := byte((d - int(n)) >> 8)
+	return ge & lt
+}
+
+// Encode returns the character for the value v.
+//
+// If v is not a valid value, Encode returns zero.
+//
+// Encode runs in constant time.
+func (a *Alphabet) Encode(v byte) byte {
+	var c byte
+	for _, s := range a.enc {
+		c |= inRange(v, s.v, s.n) & (s.lo + v - s.v)
+	}
+	return c
+}
+
+// Decode returns the value of the character c and 1, or zero
+// and 0 if c is not part of the alphabet.
+//
+// Decode runs in constant time.
+func (a *Alphabet) Decode(c byte) (byte, int) {
+	var v, ok byte
+	for _, s := range a.dec {
+		m := inRange(c, s.lo, s.n)
+		v |= m & (s.v + c - s.lo)
+		ok |= m
+	}
+	return v, int(ok & 1)
+}
@@ -0,0 +1,94 @@
+package alphabet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlphabet(t *testing.T) {
+	for _, chars := range []string{
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",
+		"0123456789ABCDEFGHIJKLMNOPQRSTUV",
+		"0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+		"zyxwvutsrqponmlkjihgfedcba",
+		"\x00\xff\x01\xfe",
+	} {
+		a := New(chars)
+		for v := 0; v < 256; v++ {
+			c := a.Encode(byte(v))
+			if v < len(chars) {
+				if c != chars[v] {
+					t.Errorf("%q: Encode(%d): expected %q, got %q", chars, v, chars[v], c)
+				}
+			} else if c != 0 {
+				t.Errorf("%q: Encode(%d): expected 0, got %q", chars, v, c)
+			}
+		}
+		for c := 0; c < 256; c++ {
+			v, ok := a.Decode(byte(c))
+			i := strings.IndexByte(chars, byte(c))
+			if i < 0 {
+				if ok != 0 || v != 0 {
+					t.Errorf("%q: Decode(%q): expected (0, 0), got (%d, %d)", chars, c, v, ok)
+				}
+				continue
+			}
+			if ok != 1 || v != byte(i) {
+				t.Errorf("%q: Decode(%q): expected (%d, 1), got (%d, %d)", chars, c, i, v, ok)
+			}
+		}
+	}
+}
+
+func TestAlphabetSpans(t *testing.T) {
+	for _, tt := range []struct {
+		chars string
+		n     int
+	}{
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", 2},
+		{"0123456789ABCDEFGHIJKLMNOPQRSTUV", 2},
+		{"0123456789ABCDEFGHJKMNPQRSTVWXYZ", 6},
+	} {
+		if n := len(New(tt.chars).enc); n != tt.n {
+			t.Errorf("%q: expected %d spans, got %d", tt.chars, tt.n, n)
+		}
+	}
+}
+
+func TestAlphabetAlias(t *testing.T) {
+	a := New("0123456789ABCDEF")
+	a.FoldCase()
+	a.Alias('O', 0)
+	for _, tt := range []struct {
+		c byte
+		v byte
+	}{
+		{'a', 10}, {'f', 15}, {'F', 15}, {'O', 0},
+	} {
+		if v, ok := a.Decode(tt.c); ok != 1 || v != tt.v {
+			t.Errorf("Decode(%q): expected (%d, 1), got (%d, %d)", tt.c, tt.v, v, ok)
+		}
+	}
+	if _, ok := a.Decode('g'); ok != 0 {
+		t.Error("Decode('g'): expected failure")
+	}
+	if c := a.Encode(10); c != 'A' {
+		t.Errorf("Encode(10): expected 'A', got %q", c)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	a.Alias('a', 1)
+}
+
+func TestNewDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	New("ABCA")
+}
@@ -0,0 +1,41 @@
+package alphabet
+
+import "github.com/ericlagergren/subtle"
+
+// FirstError records the offset of the first error found by
+// a sequence of checks without branching on which checks fail.
+//
+// Decoders call Check for every character, valid or not, and
+// inspect the result once the input has been consumed. The zero
+// value has recorded no error.
+type FirstError struct {
+	failed int // 1 once an error has been recorded
+	idx    int // offset of the first error
+}
+
+// Check records idx as the offset of the first error if bad is
+// 1 and no error has been recorded yet. bad must be 0 or 1.
+//
+// Check returns 1 if it recorded idx and 0 otherwise, so that
+// callers can record other details of the first error with
+// subtle.ConstantTimeSelect.
+//
+// Check runs in constant time.
+func (e *FirstError) Check(bad, idx int) int {
+	first := bad &^ e.failed
+	e.idx = subtle.ConstantTimeSelect(first, idx, e.idx)
+	e.failed |= first
+	return first
+}
+
+// Failed returns 1 if an error has been recorded and 0
+// otherwise.
+func (e *FirstError) Failed() int {
+	return e.failed
+}
+
+// Index returns the offset of the first error, or zero if no
+// error has been recorded.
+func (e *FirstError) Index() int {
+	return e.idx
+}
@@ -0,0 +1,22 @@
+package alphabet
+
+import "testing"
+
+func TestFirstError(t *testing.T) {
+	var e FirstError
+	if e.Failed() != 0 || e.Index() != 0 {
+		t.Fatal("zero value recorded an error")
+	}
+	for i, bad := range []int{0, 0, 1, 0, 1, 1} {
+		want := 0
+		if i == 2 {
+			want = 1
+		}
+		if first := e.Check(bad, i+10); first != want {
+			t.Fatalf("%d: expected %d, got %d", i, want, first)
+		}
+	}
+	if e.Failed() != 1 || e.Index() != 12 {
+		t.Fatalf("expected the error at 12, got (%d, %d)", e.Failed(), e.Index())
+	}
+}
@@ -0,0 +1,46 @@
+// Package base85 implements the constant-time kernels shared by
+// the base85 codecs.
+//
+// Each kernel converts between a 32-bit value and five base85
+// digits, most significant first. Division by 85 is a division
+// by a constant, which the compiler turns into a multiplication,
+// so neither direction branches on or indexes by the data.
+package base85
+
+import "github.com/ericlagergren/subtle/internal/alphabet"
+
+// EncodeBlock encodes v into the five characters dst[:5] using
+// the alphabet a.
+//
+// EncodeBlock runs in constant time.
+func EncodeBlock(a *alphabet.Alphabet, dst []byte, v uint32) {
+	_ = dst[4] // bounds check hint to compiler
+	for i := 4; i >= 0; i-- {
+		dst[i] = a.Encode(byte(v % 85))
+		v /= 85
+	}
+}
+
+// DecodeBlock decodes the five characters src[:5] using the
+// alphabet a.
+//
+// If every character is valid and the digits fit in 32 bits,
+// DecodeBlock returns the value and 1. Otherwise, it returns 0
+// and the offset of the first invalid character, or zero if the
+// digits overflow.
+//
+// DecodeBlock runs in constant time.
+func DecodeBlock(a *alphabet.Alphabet, src []byte) (v uint32, idx, ok int) {
+	_ = src[4] // bounds check hint to compiler
+	var acc uint64
+	var bad alphabet.FirstError
+	for i := 0; i < 5; i++ {
+		d, valid := a.Decode(src[i])
+		acc = acc*85 + uint64(d)
+		bad.Check(valid^1, i)
+	}
+	// 85^5-1 < 2^33, so the overflow is in bit 32. It is
+	// reported at offset zero.
+	bad.Check(int(acc>>32), 0)
+	return uint32(acc), bad.Index(), bad.Failed() ^ 1
+}
@@ -0,0 +1,47 @@
+package base85
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const chars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+func TestBlock(t *testing.T) {
+	a := alphabet.New(chars)
+	rng := rand.New(rand.NewSource(1))
+	vals := []uint32{0, 1, 84, 85, math.MaxUint32}
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, rng.Uint32())
+	}
+	var buf [5]byte
+	for _, want := range vals {
+		EncodeBlock(a, buf[:], want)
+		got, _, ok := DecodeBlock(a, buf[:])
+		if ok != 1 || got != want {
+			t.Fatalf("%q: expected (%d, 1), got (%d, %d)", buf, want, got, ok)
+		}
+	}
+}
+
+func TestDecodeBlockErr(t *testing.T) {
+	a := alphabet.New(chars)
+	for _, tc := range []struct {
+		src string
+		idx int
+	}{
+		{"#####", 0}, // 85^5-1 overflows
+		{"%nSc1", 0}, // 2^32
+		{"0000~", 4},
+		{"0~0~0", 1},
+		{"~0000", 0},
+	} {
+		_, idx, ok := DecodeBlock(a, []byte(tc.src))
+		if ok != 0 || idx != tc.idx {
+			t.Fatalf("%q: expected (%d, 0), got (%d, %d)", tc.src, tc.idx, idx, ok)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package cpu implements CPU feature detection and dispatch for
+// accelerated codec routines.
+//
+// Each accelerated implementation registers an Impl that
+// records whether the CPU supports it. Codecs consult
+// Impl.Enabled before taking the accelerated path, and tests
+// use Impls and Disable to exercise every path, including the
+// generic fallbacks, on a single machine.
+package cpu
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/sys/cpu"
+)
+
+// X86 contains the x86 features used by the codecs.
+var X86 struct {
+	HasSSSE3 bool
+}
+
+// ARM64 contains the arm64 features used by the codecs.
+var ARM64 struct {
+	HasASIMD bool
+}
+
+func init() {
+	X86.HasSSSE3 = cpu.X86.HasSSSE3
+	ARM64.HasASIMD = cpu.ARM64.HasASIMD
+}
+
+// Impl is an accelerated implementation of a routine.
+type Impl struct {
+	// Pkg is the package that registered the implementation.
+	Pkg string
+	// Name describes the implementation, like "ssse3".
+	Name string
+	// Available reports whether the CPU supports the
+	// implementation.
+	Available bool
+
+	disabled bool
+}
+
+// Enabled reports whether the implementation should be used.
+func (i *Impl) Enabled() bool {
+	return i.Available && !i.disabled
+}
+
+// Disable disables the implementation until the returned
+// function is called.
+//
+// It is intended for tests and is not safe for concurrent use
+// with Enabled.
+func (i *Impl) Disable() (restore func()) {
+	prev := i.disabled
+	i.disabled = true
+	return func() { i.disabled = prev }
+}
+
+var (
+	mu    sync.Mutex
+	impls = make(map[string][]*Impl)
+)
+
+// Register records an implementation for pkg.
+//
+// It is intended to be called during package initialization.
+func Register(pkg, name string, available bool) *Impl {
+	mu.Lock()
+	defer mu.Unlock()
+
+	impl := &Impl{
+		Pkg:       pkg,
+		Name:      name,
+		Available: available,
+	}
+	impls[pkg] = append(impls[pkg], impl)
+	sort.Slice(impls[pkg], func(i, j int) bool {
+		return impls[pkg][i].Name < impls[pkg][j].Name
+	})
+	return impl
+}
+
+// Impls returns the implementations registered for pkg, sorted
+// by name.
+func Impls(pkg string) []*Impl {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return append([]*Impl(nil), impls[pkg]...)
+}
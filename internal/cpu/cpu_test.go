@@ -0,0 +1,31 @@
+package cpu
+
+import "testing"
+
+func TestRegister(t *testing.T) {
+	b := Register("test", "b", true)
+	a := Register("test", "a", false)
+
+	got := Impls("test")
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Fatalf("unexpected implementations: %v", got)
+	}
+	if len(Impls("other")) != 0 {
+		t.Fatal("expected no implementations")
+	}
+
+	if a.Enabled() {
+		t.Fatal("unavailable implementation is enabled")
+	}
+	if !b.Enabled() {
+		t.Fatal("available implementation is disabled")
+	}
+	restore := b.Disable()
+	if b.Enabled() {
+		t.Fatal("Disable did not disable the implementation")
+	}
+	restore()
+	if !b.Enabled() {
+		t.Fatal("restore did not enable the implementation")
+	}
+}
@@ -0,0 +1,10 @@
+// Package modhex implements constant-time modhex encoding, the
+// keyboard-layout-independent hexadecimal alphabet used by
+// YubiKey one-time passwords.
+//
+// Modhex replaces the hexadecimal digits 0123456789abcdef with
+// cbdefghijklnrtuv. OTP validation servers decode attacker
+// supplied modhex that contains encrypted secrets, so every
+// character is translated without secret-dependent branches or
+// table lookups.
+package modhex
@@ -0,0 +1,131 @@
+package modhex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+const encodeModhex = "cbdefghijklnrtuv"
+
+// modhex accepts both lowercase and uppercase characters, since
+// a YubiKey types uppercase characters when caps lock is on.
+var modhex = func() *alphabet.Alphabet {
+	a := alphabet.New(encodeModhex)
+	a.FoldCase()
+	return a
+}()
+
+// ErrLength results from decoding an odd length slice.
+var ErrLength = errors.New("modhex: odd length modhex string")
+
+// InvalidByteError values describe errors resulting from an
+// invalid byte in a modhex string.
+type InvalidByteError byte
+
+func (e InvalidByteError) Error() string {
+	return fmt.Sprintf("modhex: invalid byte: %#U", rune(e))
+}
+
+// EncodedLen returns the length of an encoding of n source
+// bytes. Specifically, it returns n * 2.
+func EncodedLen(n int) int { return n * 2 }
+
+// Encode encodes src into EncodedLen(len(src)) bytes of dst.
+// As a convenience, it returns the number of bytes written to
+// dst, but this value is always EncodedLen(len(src)).
+//
+// Encode runs in constant time for the length of src.
+func Encode(dst, src []byte) int {
+	j := 0
+	for _, v := range src {
+		dst[j] = modhex.Encode(v >> 4)
+		dst[j+1] = modhex.Encode(v & 0x0f)
+		j += 2
+	}
+	return len(src) * 2
+}
+
+// AppendEncode appends the modhex encoding of src to dst and
+// returns the extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, EncodedLen(len(src)))
+	Encode(out, src)
+	return ret
+}
+
+// EncodeToString returns the modhex encoding of src.
+func EncodeToString(src []byte) string {
+	buf := AppendEncode(nil, src)
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s
+}
+
+// DecodedLen returns the length of a decoding of x source bytes.
+// Specifically, it returns x / 2.
+func DecodedLen(x int) int { return x / 2 }
+
+// Decode decodes src into DecodedLen(len(src)) bytes, returning
+// the actual number of bytes written to dst.
+//
+// Decode expects that src contains only modhex characters, in
+// either case, and that src has even length. If the input is
+// malformed, Decode returns the number of bytes decoded before
+// the error.
+//
+// src may alias dst if both begin at the same address.
+//
+// Decode runs in constant time for the length of src.
+func Decode(dst, src []byte) (int, error) {
+	var bad alphabet.FirstError
+	var badChar int
+	var acc byte
+	i := 0
+	for j := 0; j < len(src); j++ {
+		c := src[j]
+		val, ok := modhex.Decode(c)
+		first := bad.Check(ok^1, i)
+		badChar = subtle.ConstantTimeSelect(first, int(c), badChar)
+		if j%2 == 0 {
+			acc = val << 4
+		} else {
+			dst[i] = acc | val
+			i++
+		}
+	}
+
+	// Check for an invalid character before the length, like
+	// encoding/hex.
+	if bad.Failed() != 0 {
+		return bad.Index(), InvalidByteError(badChar)
+	}
+	if len(src)%2 == 1 {
+		return i, ErrLength
+	}
+	return i, nil
+}
+
+// AppendDecode appends the bytes represented by the modhex
+// data src to dst and returns the extended buffer.
+//
+// If the input is malformed, AppendDecode returns dst extended
+// by the bytes decoded before the error.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, DecodedLen(len(src)))
+	n, err := Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by the modhex
+// string s.
+//
+// If the input is malformed, DecodeString returns the bytes
+// decoded before the error.
+func DecodeString(s string) ([]byte, error) {
+	src := []byte(s)
+	n, err := Decode(src, src)
+	return src[:n], err
+}
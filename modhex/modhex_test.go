@@ -0,0 +1,92 @@
+package modhex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var vectors = []struct {
+	hex, encoded string
+}{
+	{"", ""},
+	{"0123456789abcdef", "cbdefghijklnrtuv"},
+	{"2d344e83", "dteffuje"},
+	{"69b6481c8baba2b60e8f22179b58cd56", "hknhfjbrjnlnldnhcujvddbikngjrtgh"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, v := range vectors {
+		src, _ := hex.DecodeString(v.hex)
+		if got := EncodeToString(src); got != v.encoded {
+			t.Fatalf("EncodeToString(%s): expected %q, got %q", v.hex, v.encoded, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, v := range vectors {
+		want, _ := hex.DecodeString(v.hex)
+		for _, s := range []string{v.encoded, strings.ToUpper(v.encoded)} {
+			got, err := DecodeString(s)
+			if err != nil {
+				t.Fatalf("DecodeString(%q): %v", s, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("DecodeString(%q): expected %x, got %x", s, want, got)
+			}
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		src string
+		n   int
+		err error
+	}{
+		{"c", 0, ErrLength},
+		{"cbd", 1, ErrLength},
+		{"cbda", 1, InvalidByteError('a')},
+		{"0b", 0, InvalidByteError('0')},
+		{"cbdefghijklnrtuvcbdefgh0cb", 11, InvalidByteError('0')},
+		{"cbdm", 1, InvalidByteError('m')},
+		{"cbdefm", 2, InvalidByteError('m')},
+	} {
+		dst := make([]byte, DecodedLen(len(tc.src)))
+		n, err := Decode(dst, []byte(tc.src))
+		if n != tc.n || !errors.Is(err, tc.err) {
+			t.Fatalf("Decode(%q): expected (%d, %v), got (%d, %v)", tc.src, tc.n, tc.err, n, err)
+		}
+	}
+}
+
+// TestHex checks that modhex is a substitution of hex.
+func TestHex(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	r := strings.NewReplacer(
+		"0", "c", "1", "b", "2", "d", "3", "e",
+		"4", "f", "5", "g", "6", "h", "7", "i",
+		"8", "j", "9", "k", "a", "l", "b", "n",
+		"c", "r", "d", "t", "e", "u", "f", "v",
+	)
+	for n := 0; n < 100; n++ {
+		src := make([]byte, n)
+		rng.Read(src)
+		want := r.Replace(hex.EncodeToString(src))
+		got := EncodeToString(src)
+		if got != want {
+			t.Fatalf("EncodeToString(%x): expected %q, got %q", src, want, got)
+		}
+		dec, err := DecodeString(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(dec, src) {
+			t.Fatalf("DecodeString(%q): expected %x, got %x", got, src, dec)
+		}
+	}
+}
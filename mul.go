@@ -0,0 +1,43 @@
+package subtle
+
+import "math/bits"
+
+// ConstantTimeMul64 returns the 128-bit product of x and y:
+// (hi, lo) = x * y with the product bits' upper half returned
+// in hi and the lower half returned in lo.
+//
+// Some CPUs have multipliers that finish early for small
+// operands, which leaks information about secret inputs. The
+// implementation is chosen per architecture:
+//
+//   - amd64: MUL has a fixed latency on all known x86-64
+//     implementations, so the hardware multiplier is used.
+//   - arm64: MUL and UMULH have a fixed latency on all known
+//     ARMv8 implementations, so the hardware multiplier is used.
+//   - Everything else, including the 32-bit architectures
+//     where a 64-bit multiply is synthesized from 32-bit
+//     multiplies (some of which terminate early), uses a
+//     branch-free shift-and-add loop.
+//
+// Building with the "ctmul" build tag forces the shift-and-add
+// loop on every architecture.
+func ConstantTimeMul64(x, y uint64) (hi, lo uint64) {
+	return mul64(x, y)
+}
+
+// mul64Generic is a constant-time shift-and-add implementation
+// of ConstantTimeMul64 that does not use the hardware
+// multiplier.
+func mul64Generic(x, y uint64) (hi, lo uint64) {
+	for i := 0; i < 64; i++ {
+		// mask is all ones if bit i of y is set and zero
+		// otherwise.
+		mask := -((y >> i) & 1)
+		// (x << i) as a 128-bit integer. Go defines x>>64 as
+		// zero, which handles i == 0.
+		var carry uint64
+		lo, carry = bits.Add64(lo, (x<<i)&mask, 0)
+		hi, _ = bits.Add64(hi, (x>>(64-i))&mask, carry)
+	}
+	return hi, lo
+}
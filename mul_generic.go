@@ -0,0 +1,7 @@
+//go:build (!amd64 && !arm64) || ctmul
+
+package subtle
+
+func mul64(x, y uint64) (hi, lo uint64) {
+	return mul64Generic(x, y)
+}
@@ -0,0 +1,9 @@
+//go:build (amd64 || arm64) && !ctmul
+
+package subtle
+
+import "math/bits"
+
+func mul64(x, y uint64) (hi, lo uint64) {
+	return bits.Mul64(x, y)
+}
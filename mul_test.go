@@ -0,0 +1,52 @@
+package subtle
+
+import (
+	"math/bits"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestConstantTimeMul64(t *testing.T) {
+	seed := uint64(time.Now().UnixNano())
+	t.Logf("seed: %#x", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	edge := []uint64{0, 1, 2, 1<<32 - 1, 1 << 32, 1<<63 - 1, 1 << 63, 1<<64 - 1}
+	check := func(x, y uint64) {
+		wantHi, wantLo := bits.Mul64(x, y)
+		for _, fn := range []struct {
+			name string
+			fn   func(x, y uint64) (uint64, uint64)
+		}{
+			{"ConstantTimeMul64", ConstantTimeMul64},
+			{"mul64Generic", mul64Generic},
+		} {
+			hi, lo := fn.fn(x, y)
+			if hi != wantHi || lo != wantLo {
+				t.Fatalf("%s(%#x, %#x) = (%#x, %#x), expected (%#x, %#x)",
+					fn.name, x, y, hi, lo, wantHi, wantLo)
+			}
+		}
+	}
+	for _, x := range edge {
+		for _, y := range edge {
+			check(x, y)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		check(rng.Uint64(), rng.Uint64())
+	}
+}
+
+var mulSink uint64
+
+func BenchmarkConstantTimeMul64(b *testing.B) {
+	x, y := uint64(0x0123456789abcdef), uint64(0xfedcba9876543210)
+	for i := 0; i < b.N; i++ {
+		hi, lo := ConstantTimeMul64(x, y)
+		x, y = lo, hi|1
+	}
+	mulSink = x
+}
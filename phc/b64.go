@@ -0,0 +1,171 @@
+package phc
+
+import (
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+var (
+	// b64 is the "B64" alphabet from the PHC string format
+	// specification: standard base64 without padding.
+	b64 = alphabet.New("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")
+	// cryptB64 is the crypt(3) alphabet.
+	cryptB64 = alphabet.New("./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+)
+
+// appendB64 appends the unpadded B64 encoding of src to dst.
+//
+// appendB64 runs in constant time for the length of src.
+func appendB64(dst, src []byte) []byte {
+	for len(src) > 0 {
+		var v uint32
+		n := 3
+		if len(src) < n {
+			n = len(src)
+		}
+		for i := 0; i < n; i++ {
+			v |= uint32(src[i]) << (16 - 8*i)
+		}
+		for i := 0; i <= n; i++ {
+			dst = append(dst, b64.Encode(byte(v>>(18-6*i))&63))
+		}
+		src = src[n:]
+	}
+	return dst
+}
+
+// decodeB64 decodes the unpadded B64 string s.
+//
+// If s is malformed or not canonical, decodeB64 returns the
+// offset of the first invalid byte and 0.
+//
+// decodeB64 runs in constant time for the length of s.
+func decodeB64(s string) ([]byte, int, int) {
+	if len(s)%4 == 1 {
+		return nil, len(s), 0
+	}
+	dst := make([]byte, len(s)*3/4)
+
+	var bad alphabet.FirstError
+	j := 0
+	for i := 0; i < len(s); i += 4 {
+		n := 4
+		if len(s)-i < n {
+			n = len(s) - i
+		}
+		var v uint32
+		for k := 0; k < n; k++ {
+			d, ok := b64.Decode(s[i+k])
+			v |= uint32(d) << (18 - 6*k)
+			bad.Check(ok^1, i+k)
+		}
+		for k := 0; k < n-1; k++ {
+			dst[j] = byte(v >> (16 - 8*k))
+			j++
+		}
+		// The unused bits of a partial group must be zero.
+		if n < 4 {
+			unused := byte(v >> (16 - 8*(n-1)))
+			bad.Check(subtle.ConstantTimeByteEq(unused, 0)^1, len(s)-1)
+		}
+	}
+	if bad.Failed() != 0 {
+		subtle.Wipe(dst)
+		return nil, bad.Index(), 0
+	}
+	return dst, 0, 1
+}
+
+// SHA-crypt encodes the digest in groups of three bytes in
+// a fixed, shuffled order. Each permutation lists the digest
+// bytes in the order they are encoded.
+var (
+	perm256 = []int{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15,
+		25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29, 31, 30,
+	}
+	perm512 = []int{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47,
+		5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51, 31, 52,
+		10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57,
+		37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19, 62, 20, 41, 63,
+	}
+)
+
+// cryptLen returns the length of the SHA-crypt encoding of
+// a digest of n bytes.
+func cryptLen(n int) int {
+	m := n / 3 * 4
+	if r := n % 3; r > 0 {
+		m += r + 1
+	}
+	return m
+}
+
+// appendCrypt appends the SHA-crypt encoding of the digest src,
+// whose bytes are encoded in the order perm, to dst.
+//
+// Each group of three bytes is a big-endian 24-bit value that
+// is encoded least significant six bits first. A final group of
+// r < 3 bytes is encoded in r+1 characters.
+//
+// appendCrypt runs in constant time.
+func appendCrypt(dst, src []byte, perm []int) []byte {
+	for i := 0; i < len(perm); i += 3 {
+		g := perm[i:]
+		if len(g) > 3 {
+			g = g[:3]
+		}
+		var v uint32
+		for _, p := range g {
+			v = v<<8 | uint32(src[p])
+		}
+		for k := 0; k <= len(g); k++ {
+			dst = append(dst, cryptB64.Encode(byte(v)&63))
+			v >>= 6
+		}
+	}
+	return dst
+}
+
+// decodeCrypt decodes the SHA-crypt encoding s of a digest whose
+// bytes are encoded in the order perm.
+//
+// If s is malformed or not canonical, decodeCrypt returns the
+// offset of the first invalid byte and 0.
+//
+// decodeCrypt runs in constant time for the length of s.
+func decodeCrypt(s string, perm []int) ([]byte, int, int) {
+	if len(s) != cryptLen(len(perm)) {
+		return nil, len(s), 0
+	}
+	dst := make([]byte, len(perm))
+
+	var bad alphabet.FirstError
+	j := 0
+	for i := 0; i < len(perm); i += 3 {
+		g := perm[i:]
+		if len(g) > 3 {
+			g = g[:3]
+		}
+		var v uint32
+		for k := 0; k <= len(g); k++ {
+			d, ok := cryptB64.Decode(s[j+k])
+			v |= uint32(d) << (6 * k)
+			bad.Check(ok^1, j+k)
+		}
+		j += len(g) + 1
+		// The unused bits of a partial group must be zero.
+		over := int(v >> (8 * len(g)))
+		bad.Check(subtle.ConstantTimeEq(int32(over), 0)^1, j-1)
+		for k := len(g) - 1; k >= 0; k-- {
+			dst[g[k]] = byte(v)
+			v >>= 8
+		}
+	}
+	if bad.Failed() != 0 {
+		subtle.Wipe(dst)
+		return nil, bad.Index(), 0
+	}
+	return dst, 0, 1
+}
@@ -0,0 +1,13 @@
+// Package phc parses and formats password hash strings in the
+// PHC string format, like
+//
+//	$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc
+//
+// and the SHA-crypt modular crypt formats, $5$ and $6$.
+//
+// The salt and hash segments are decoded and encoded without
+// secret-dependent branches or table lookups: the standard
+// base64 alphabet without padding for PHC strings, and the
+// crypt(3) alphabet for SHA-crypt. Decoding is strict, so that
+// formatting a parsed string reproduces it exactly.
+package phc
@@ -0,0 +1,410 @@
+package phc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Hash is a parsed password hash string.
+type Hash struct {
+	// ID identifies the hash function, like "argon2id", or "5"
+	// and "6" for SHA-crypt.
+	ID string
+	// Version is the decimal version of the hash function, like
+	// "19", or empty if the string does not have one.
+	//
+	// SHA-crypt strings do not have a version.
+	Version string
+	// Params are the parameters of the hash function in the
+	// order they appear in the string.
+	//
+	// The only SHA-crypt parameter is "rounds".
+	Params []Param
+	// Salt is the decoded salt, or nil if the string does not
+	// have one.
+	//
+	// SHA-crypt salts are not encoded, so Salt is the salt
+	// string itself.
+	Salt []byte
+	// Digest is the decoded hash output, or nil if the string
+	// does not have one.
+	Digest []byte
+}
+
+// Param is a hash function parameter.
+type Param struct {
+	Name  string
+	Value string
+}
+
+var (
+	// ErrInvalidFormat is returned by Parse when the string is
+	// not a valid PHC or SHA-crypt string.
+	ErrInvalidFormat = errors.New("phc: invalid format")
+	// ErrInvalidParam is returned by Parse when the version or
+	// a parameter is malformed or repeated.
+	ErrInvalidParam = errors.New("phc: invalid parameter")
+	// ErrInvalidLength is returned by Append when a SHA-crypt
+	// digest is not the size of the hash function's output.
+	ErrInvalidLength = errors.New("phc: invalid digest length")
+)
+
+// CorruptInputError is returned when the salt or hash contains
+// an invalid character, has an invalid length, or is not
+// canonically encoded. Its value is the offset of the first
+// invalid byte.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal phc data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// maxSaltLen is the maximum length of a SHA-crypt salt.
+//
+// Longer salts are silently truncated by crypt(3), so they
+// cannot be reproduced.
+const maxSaltLen = 16
+
+// minRounds and maxRounds are the limits on the SHA-crypt
+// rounds parameter. crypt(3) clamps other values, so they cannot
+// be reproduced either.
+const (
+	minRounds = 1000
+	maxRounds = 999999999
+)
+
+// cryptPerm returns the SHA-crypt digest permutation for id, or
+// nil if id is not a SHA-crypt identifier.
+func cryptPerm(id string) []int {
+	switch id {
+	case "5":
+		return perm256
+	case "6":
+		return perm512
+	default:
+		return nil
+	}
+}
+
+// Parse parses a password hash string in either the PHC string
+// format,
+//
+//	$<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+//
+// or a SHA-crypt format,
+//
+//	$5$[rounds=<rounds>$]<salt>[$<hash>]
+//	$6$[rounds=<rounds>$]<salt>[$<hash>]
+//
+// SHA-crypt salts are at most 16 characters from the crypt(3)
+// alphabet [./0-9A-Za-z], and rounds must be in [1000,
+// 999999999].
+//
+// The salt and hash are decoded in constant time. Parse only
+// accepts canonical strings, so the String method of the result
+// returns s.
+func Parse(s string) (*Hash, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, ErrInvalidFormat
+	}
+	id := s[1:]
+	if i := strings.IndexByte(id, '$'); i >= 0 {
+		id = id[:i]
+	}
+	if !validName(id) {
+		return nil, ErrInvalidFormat
+	}
+	if perm := cryptPerm(id); perm != nil {
+		return parseCrypt(s, id, perm)
+	}
+	return parsePHC(s, id)
+}
+
+// parsePHC parses s in the PHC string format.
+func parsePHC(s, id string) (*Hash, error) {
+	h := &Hash{ID: id}
+	fields := strings.Split(s[1:], "$")[1:]
+	off := 1 + len(id) + 1
+	next := func() string {
+		f := fields[0]
+		fields = fields[1:]
+		off += len(f) + 1
+		return f
+	}
+	for _, f := range fields {
+		if f == "" {
+			return nil, ErrInvalidFormat
+		}
+	}
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		v := next()[2:]
+		if !validDecimal(v) {
+			return nil, ErrInvalidParam
+		}
+		h.Version = v
+	}
+	if len(fields) > 0 && strings.IndexByte(fields[0], '=') >= 0 {
+		for _, p := range strings.Split(next(), ",") {
+			name, value, ok := strings.Cut(p, "=")
+			if !ok || !validName(name) || !validValue(value) {
+				return nil, ErrInvalidParam
+			}
+			if _, ok := h.Param(name); ok {
+				return nil, ErrInvalidParam
+			}
+			h.Params = append(h.Params, Param{Name: name, Value: value})
+		}
+	}
+	if len(fields) > 2 {
+		return nil, ErrInvalidFormat
+	}
+	if len(fields) > 0 {
+		start := off
+		salt, idx, ok := decodeB64(next())
+		if ok == 0 {
+			return nil, CorruptInputError(start + idx)
+		}
+		h.Salt = salt
+	}
+	if len(fields) > 0 {
+		start := off
+		digest, idx, ok := decodeB64(next())
+		if ok == 0 {
+			return nil, CorruptInputError(start + idx)
+		}
+		h.Digest = digest
+	}
+	return h, nil
+}
+
+// parseCrypt parses s in a SHA-crypt format.
+func parseCrypt(s, id string, perm []int) (*Hash, error) {
+	h := &Hash{ID: id}
+	off := 1 + len(id) + 1
+	if off > len(s) {
+		return nil, ErrInvalidFormat
+	}
+	rest := s[off:]
+	if strings.HasPrefix(rest, "rounds=") {
+		p, tail, ok := strings.Cut(rest, "$")
+		if !ok {
+			return nil, ErrInvalidFormat
+		}
+		v := p[len("rounds="):]
+		if !validRounds(v) {
+			return nil, ErrInvalidParam
+		}
+		h.Params = []Param{{Name: "rounds", Value: v}}
+		off += len(p) + 1
+		rest = tail
+	}
+	salt, hash, ok := strings.Cut(rest, "$")
+	if !validSalt(salt) {
+		return nil, ErrInvalidFormat
+	}
+	h.Salt = []byte(salt)
+	if ok {
+		off += len(salt) + 1
+		digest, idx, ok := decodeCrypt(hash, perm)
+		if ok == 0 {
+			return nil, CorruptInputError(off + idx)
+		}
+		h.Digest = digest
+	}
+	return h, nil
+}
+
+// Param returns the value of the named parameter and reports
+// whether it is present.
+func (h *Hash) Param(name string) (string, bool) {
+	for _, p := range h.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns the canonical encoding of h, or the empty
+// string if h cannot be encoded. See Append.
+func (h *Hash) String() string {
+	b, err := h.Append(nil)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Append appends the canonical encoding of h to dst and returns
+// the extended buffer.
+//
+// Append only encodes hashes that Parse accepts. It returns an
+// error if h has an invalid identifier, version, or parameter,
+// if it has a hash but no salt, or if a SHA-crypt hash does not
+// have a valid salt or a digest of the correct length.
+//
+// The salt and hash are encoded in constant time.
+func (h *Hash) Append(dst []byte) ([]byte, error) {
+	if err := h.validate(); err != nil {
+		return dst, err
+	}
+	dst = append(dst, '$')
+	dst = append(dst, h.ID...)
+	if perm := cryptPerm(h.ID); perm != nil {
+		dst = append(dst, '$')
+		for _, p := range h.Params {
+			dst = append(dst, p.Name...)
+			dst = append(dst, '=')
+			dst = append(dst, p.Value...)
+			dst = append(dst, '$')
+		}
+		dst = append(dst, h.Salt...)
+		if h.Digest != nil {
+			dst = append(dst, '$')
+			dst = appendCrypt(dst, h.Digest, perm)
+		}
+		return dst, nil
+	}
+	if h.Version != "" {
+		dst = append(dst, "$v="...)
+		dst = append(dst, h.Version...)
+	}
+	for i, p := range h.Params {
+		if i == 0 {
+			dst = append(dst, '$')
+		} else {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, p.Name...)
+		dst = append(dst, '=')
+		dst = append(dst, p.Value...)
+	}
+	if h.Salt != nil {
+		dst = append(dst, '$')
+		dst = appendB64(dst, h.Salt)
+		if h.Digest != nil {
+			dst = append(dst, '$')
+			dst = appendB64(dst, h.Digest)
+		}
+	}
+	return dst, nil
+}
+
+// validate checks that h can be encoded as a string that Parse
+// accepts.
+func (h *Hash) validate() error {
+	if !validName(h.ID) {
+		return ErrInvalidFormat
+	}
+	if perm := cryptPerm(h.ID); perm != nil {
+		if h.Version != "" || len(h.Params) > 1 {
+			return ErrInvalidParam
+		}
+		for _, p := range h.Params {
+			if p.Name != "rounds" || !validRounds(p.Value) {
+				return ErrInvalidParam
+			}
+		}
+		if !validSalt(string(h.Salt)) {
+			return ErrInvalidFormat
+		}
+		if h.Digest != nil && len(h.Digest) != len(perm) {
+			return ErrInvalidLength
+		}
+		return nil
+	}
+	if h.Version != "" && !validDecimal(h.Version) {
+		return ErrInvalidParam
+	}
+	for i, p := range h.Params {
+		if !validName(p.Name) || !validValue(p.Value) {
+			return ErrInvalidParam
+		}
+		// A leading "v" parameter would be parsed as the
+		// version.
+		if i == 0 && h.Version == "" && p.Name == "v" {
+			return ErrInvalidParam
+		}
+		for _, q := range h.Params[:i] {
+			if q.Name == p.Name {
+				return ErrInvalidParam
+			}
+		}
+	}
+	// Empty fields are not allowed.
+	if h.Salt != nil && len(h.Salt) == 0 ||
+		h.Digest != nil && (len(h.Digest) == 0 || h.Salt == nil) {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// validName reports whether s is a valid function identifier or
+// parameter name: 1 to 32 characters in [a-z0-9-].
+func validName(s string) bool {
+	if len(s) == 0 || len(s) > 32 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// validValue reports whether s is a valid parameter value: one
+// or more characters in [a-zA-Z0-9/+.-].
+func validValue(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+			'0' <= c && c <= '9' || c == '/' || c == '+' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// validDecimal reports whether s is a canonical decimal integer:
+// one to nine digits without leading zeros.
+func validDecimal(s string) bool {
+	if len(s) == 0 || len(s) > 9 || (s[0] == '0' && len(s) > 1) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validRounds reports whether s is a valid SHA-crypt rounds
+// parameter.
+func validRounds(s string) bool {
+	if !validDecimal(s) {
+		return false
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= minRounds && n <= maxRounds
+}
+
+// validSalt reports whether s is a valid SHA-crypt salt: at most
+// 16 characters in [./0-9A-Za-z].
+func validSalt(s string) bool {
+	if len(s) > maxSaltLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !cryptB64.Contains(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package phc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want *Hash
+	}{
+		{
+			// From the Argon2 reference implementation.
+			s: "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+			want: &Hash{
+				ID:      "argon2id",
+				Version: "19",
+				Params:  []Param{{"m", "65536"}, {"t", "2"}, {"p", "1"}},
+				Salt:    []byte("somesalt"),
+				Digest:  unhex("09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"),
+			},
+		},
+		{
+			s:    "$argon2i",
+			want: &Hash{ID: "argon2i"},
+		},
+		{
+			s:    "$argon2i$v=19",
+			want: &Hash{ID: "argon2i", Version: "19"},
+		},
+		{
+			s: "$scrypt$ln=15,r=8,p=1$c29tZXNhbHQ",
+			want: &Hash{
+				ID:     "scrypt",
+				Params: []Param{{"ln", "15"}, {"r", "8"}, {"p", "1"}},
+				Salt:   []byte("somesalt"),
+			},
+		},
+		{
+			s: "$pbkdf2-sha256$i=1000$AAE$AAECAw",
+			want: &Hash{
+				ID:     "pbkdf2-sha256",
+				Params: []Param{{"i", "1000"}},
+				Salt:   []byte{0, 1},
+				Digest: []byte{0, 1, 2, 3},
+			},
+		},
+		// From "Unix crypt using SHA-256 and SHA-512".
+		{
+			s: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+			want: &Hash{
+				ID:     "5",
+				Salt:   []byte("saltstring"),
+				Digest: unhex("ece9807faae7f7203489a324e617df4c8b649a234792134358d845655d4d107a"),
+			},
+		},
+		{
+			s: "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA",
+			want: &Hash{
+				ID:     "5",
+				Params: []Param{{"rounds", "10000"}},
+				Salt:   []byte("saltstringsaltst"),
+				Digest: unhex("03e9cd30a56ff0840423bfe10cb5fb97c376d7ea454d69ccea095dfd40b614ca"),
+			},
+		},
+		{
+			s: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+			want: &Hash{
+				ID:     "6",
+				Salt:   []byte("saltstring"),
+				Digest: unhex("2b209d0f3abe5abc1b24521555baa2b94d0943dae13e85666e7946e24de2323733cc538877a227437ac5f8ede5986c71a987079aa165ef8a1bda94a5916aceff"),
+			},
+		},
+		{
+			s: "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+			want: &Hash{
+				ID:     "6",
+				Params: []Param{{"rounds", "10000"}},
+				Salt:   []byte("saltstringsaltst"),
+				Digest: unhex("041a88ea0af968aa39849900094f5e07e485077ec938905358aae3590af8e63b588cec9ae3c89e8dcd9a9ad234e81788b7dd9d2737deafadaa53d74c8bd11f3b"),
+			},
+		},
+		{
+			s:    "$6$rounds=5000$salt",
+			want: &Hash{ID: "6", Params: []Param{{"rounds", "5000"}}, Salt: []byte("salt")},
+		},
+	} {
+		got, err := Parse(tc.s)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.s, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("Parse(%q): expected %+v, got %+v", tc.s, tc.want, got)
+		}
+		if s := got.String(); s != tc.s {
+			t.Fatalf("expected %q, got %q", tc.s, s)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"", ErrInvalidFormat},
+		{"argon2id", ErrInvalidFormat},
+		{"$", ErrInvalidFormat},
+		{"$Argon2id", ErrInvalidFormat},
+		{"$argon2id$", ErrInvalidFormat},
+		{"$argon2id$$c29tZXNhbHQ", ErrInvalidFormat},
+		{"$argon2id$c29tZXNhbHQ$AAAA$AAAA", ErrInvalidFormat},
+		{"$abcdefghijklmnopqrstuvwxyz0123456", ErrInvalidFormat},
+		{"$5", ErrInvalidFormat},
+		{"$5$rounds=5000", ErrInvalidFormat},
+		{"$5$saltstringsaltstr", ErrInvalidFormat},
+		{"$argon2id$v=", ErrInvalidParam},
+		{"$argon2id$v=019", ErrInvalidParam},
+		{"$argon2id$v=1x", ErrInvalidParam},
+		{"$argon2id$m=", ErrInvalidParam},
+		{"$argon2id$=1", ErrInvalidParam},
+		{"$argon2id$m=1,", ErrInvalidParam},
+		{"$argon2id$m=1,m=2", ErrInvalidParam},
+		{"$argon2id$M=1", ErrInvalidParam},
+		{"$argon2id$m=1$", ErrInvalidFormat},
+		{"$argon2id$m=a_b", ErrInvalidParam},
+		{"$5$rounds=$salt", ErrInvalidParam},
+		{"$5$rounds=0$salt", ErrInvalidParam},
+		{"$5$rounds=05000$salt", ErrInvalidParam},
+		{"$5$rounds=999$salt", ErrInvalidParam},
+		{"$5$rounds=1000000000$salt", ErrInvalidParam},
+		{"$5$sa*t", ErrInvalidFormat},
+		{"$6$salt\n$", ErrInvalidFormat},
+		{"$argon2id$c29tZXNhbHQ=", ErrInvalidParam},
+		{"$argon2id$c29tZXNhbH.", CorruptInputError(20)},
+		{"$argon2id$c29t*XNhbHQ", CorruptInputError(14)},
+		{"$argon2id$c29tZXNhbHQ$A", CorruptInputError(23)},
+		// Non-zero unused bits.
+		{"$argon2id$AAF", CorruptInputError(12)},
+		{"$argon2id$AB", CorruptInputError(11)},
+		{"$argon2id$m=1$AAAA$AAAAAB", CorruptInputError(24)},
+		{"$5$salt$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc", CorruptInputError(50)},
+		{"$5$salt$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5a", CorruptInputError(52)},
+		{"$5$salt$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5$", CorruptInputError(52)},
+		{"$5$salt$5B8vYYiY.CVt1RlTT_8KbXBH3hsxY/GNooZaBBGWEc5", CorruptInputError(25)},
+		{"$5$salt$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEcz", CorruptInputError(50)},
+		{"$6$salt$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35in.2", CorruptInputError(93)},
+	} {
+		_, err := Parse(tc.s)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("Parse(%q): expected %v, got %v", tc.s, tc.err, err)
+		}
+	}
+}
+
+func TestAppendInvalid(t *testing.T) {
+	salt := []byte("salt")
+	for _, tc := range []struct {
+		h   Hash
+		err error
+	}{
+		{Hash{}, ErrInvalidFormat},
+		{Hash{ID: "A"}, ErrInvalidFormat},
+		{Hash{ID: "5", Salt: salt, Digest: []byte{1}}, ErrInvalidLength},
+		{Hash{ID: "6", Salt: salt, Digest: make([]byte, 32)}, ErrInvalidLength},
+		{Hash{ID: "5", Salt: []byte("saltstringsaltstr")}, ErrInvalidFormat},
+		{Hash{ID: "5", Salt: []byte("sa$t")}, ErrInvalidFormat},
+		{Hash{ID: "5", Version: "1", Salt: salt}, ErrInvalidParam},
+		{Hash{ID: "5", Params: []Param{{"rounds", "999"}}, Salt: salt}, ErrInvalidParam},
+		{Hash{ID: "5", Params: []Param{{"r", "1000"}}, Salt: salt}, ErrInvalidParam},
+		{Hash{ID: "argon2id", Version: "019"}, ErrInvalidParam},
+		{Hash{ID: "argon2id", Params: []Param{{"v", "1"}}}, ErrInvalidParam},
+		{Hash{ID: "argon2id", Params: []Param{{"m", "1"}, {"m", "2"}}}, ErrInvalidParam},
+		{Hash{ID: "argon2id", Params: []Param{{"m", "$"}}}, ErrInvalidParam},
+		{Hash{ID: "argon2id", Salt: []byte{}}, ErrInvalidFormat},
+		{Hash{ID: "argon2id", Digest: []byte{1}}, ErrInvalidFormat},
+		{Hash{ID: "argon2id", Salt: salt, Digest: []byte{}}, ErrInvalidFormat},
+	} {
+		_, err := tc.h.Append(nil)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%+v: expected %v, got %v", tc.h, tc.err, err)
+		}
+		if s := tc.h.String(); s != "" {
+			t.Fatalf("%+v: expected an empty string, got %q", tc.h, s)
+		}
+	}
+}
+
+// TestB64 compares the PHC encoding of random data against
+// encoding/base64.
+func TestB64(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		src := make([]byte, n)
+		rng.Read(src)
+		want := base64.RawStdEncoding.EncodeToString(src)
+		got := string(appendB64(nil, src))
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+		dec, _, ok := decodeB64(got)
+		if ok != 1 || !bytes.Equal(dec, src) {
+			t.Fatalf("decodeB64(%q): expected %x, got %x", got, src, dec)
+		}
+	}
+}
+
+// TestCrypt tests round trips of random SHA-crypt digests.
+func TestCrypt(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, perm := range [][]int{perm256, perm512} {
+		for i := 0; i < 100; i++ {
+			src := make([]byte, len(perm))
+			rng.Read(src)
+			s := string(appendCrypt(nil, src, perm))
+			if len(s) != cryptLen(len(perm)) {
+				t.Fatalf("expected %d characters, got %d", cryptLen(len(perm)), len(s))
+			}
+			got, _, ok := decodeCrypt(s, perm)
+			if ok != 1 || !bytes.Equal(got, src) {
+				t.Fatalf("decodeCrypt(%q): expected %x, got %x", s, src, got)
+			}
+		}
+	}
+}
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,11 @@
+// Package redact implements wrapper types that keep secrets out
+// of formatted output.
+//
+// Printing a wrapped secret with the fmt package always produces
+// a placeholder, closing the accidental
+//
+//	fmt.Printf("%x", key)
+//
+// hole. The secret is only revealed by an explicit call to its
+// Expose or ExposeHex method.
+package redact
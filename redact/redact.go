@@ -0,0 +1,85 @@
+package redact
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ericlagergren/subtle/hex"
+)
+
+// Placeholder is printed in place of a redacted secret.
+const Placeholder = "[REDACTED]"
+
+// Bytes is a secret byte slice.
+//
+// Formatting Bytes with the fmt package prints Placeholder
+// regardless of the verb and flags, even when Bytes is nested
+// inside another value. Use Expose or ExposeHex to access the
+// secret.
+type Bytes []byte
+
+var (
+	_ fmt.Formatter = Bytes(nil)
+	_ fmt.Stringer  = Bytes(nil)
+)
+
+// Expose returns the secret.
+func (b Bytes) Expose() []byte {
+	return b
+}
+
+// ExposeHex returns the secret in lowercase hexadecimal.
+//
+// ExposeHex runs in constant time for the length of the secret.
+func (b Bytes) ExposeHex() string {
+	return hex.EncodeToString(b)
+}
+
+// String returns Placeholder.
+func (b Bytes) String() string {
+	return Placeholder
+}
+
+// Format implements fmt.Formatter.
+//
+// It always prints Placeholder.
+func (b Bytes) Format(s fmt.State, verb rune) {
+	io.WriteString(s, Placeholder)
+}
+
+// String is a secret string.
+//
+// Formatting String with the fmt package prints Placeholder
+// regardless of the verb and flags, even when String is nested
+// inside another value. Use Expose or ExposeHex to access the
+// secret.
+type String string
+
+var (
+	_ fmt.Formatter = String("")
+	_ fmt.Stringer  = String("")
+)
+
+// Expose returns the secret.
+func (v String) Expose() string {
+	return string(v)
+}
+
+// ExposeHex returns the secret in lowercase hexadecimal.
+//
+// ExposeHex runs in constant time for the length of the secret.
+func (v String) ExposeHex() string {
+	return hex.EncodeToString([]byte(v))
+}
+
+// String returns Placeholder.
+func (v String) String() string {
+	return Placeholder
+}
+
+// Format implements fmt.Formatter.
+//
+// It always prints Placeholder.
+func (v String) Format(s fmt.State, verb rune) {
+	io.WriteString(s, Placeholder)
+}
@@ -0,0 +1,104 @@
+package redact
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	key := Bytes{0xde, 0xad, 0xbe, 0xef}
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"%x", Placeholder},
+		{"%X", Placeholder},
+		{"%s", Placeholder},
+		{"%v", Placeholder},
+		{"%#v", Placeholder},
+		{"%q", Placeholder},
+		{"%d", Placeholder},
+		{"%+x", Placeholder},
+		{"%+s", Placeholder},
+		{"%+v", Placeholder},
+		{"%+d", Placeholder},
+	} {
+		got := fmt.Sprintf(tc.format, key)
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+	if got := key.String(); got != Placeholder {
+		t.Errorf("String: expected %q, got %q", Placeholder, got)
+	}
+	if got := fmt.Sprint(key); got != Placeholder {
+		t.Errorf("Sprint: expected %q, got %q", Placeholder, got)
+	}
+	if got := fmt.Sprintf("%x", key.Expose()); got != "deadbeef" {
+		t.Errorf("Expose: expected %q, got %q", "deadbeef", got)
+	}
+	if got := key.ExposeHex(); got != "deadbeef" {
+		t.Errorf("ExposeHex: expected %q, got %q", "deadbeef", got)
+	}
+}
+
+func TestBytesLong(t *testing.T) {
+	key := make(Bytes, 1000)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	want := fmt.Sprintf("%x", []byte(key))
+	if got := key.ExposeHex(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestString(t *testing.T) {
+	pw := String("hunter2")
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"%x", Placeholder},
+		{"%s", Placeholder},
+		{"%v", Placeholder},
+		{"%q", Placeholder},
+		{"%+x", Placeholder},
+		{"%+s", Placeholder},
+		{"%+v", Placeholder},
+	} {
+		got := fmt.Sprintf(tc.format, pw)
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+	if got := pw.Expose(); got != "hunter2" {
+		t.Errorf("Expose: expected %q, got %q", "hunter2", got)
+	}
+	if got := pw.ExposeHex(); got != "68756e74657232" {
+		t.Errorf("ExposeHex: expected %q, got %q", "68756e74657232", got)
+	}
+}
+
+// TestNested tests that verbs that fmt applies recursively do
+// not reveal nested secrets.
+func TestNested(t *testing.T) {
+	type creds struct {
+		Name string
+		Key  Bytes
+		PW   String
+	}
+	v := creds{Name: "a", Key: Bytes{1, 2, 3}, PW: "hunter2"}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%+s", "%x", "%+x", "%q"} {
+		for _, x := range []interface{}{v, &v, []creds{v}, map[string]creds{"a": v}} {
+			got := fmt.Sprintf(format, x)
+			if strings.Contains(got, "010203") || strings.Contains(got, "hunter2") {
+				t.Errorf("%s: secret leaked: %q", format, got)
+			}
+			if !strings.Contains(got, Placeholder) {
+				t.Errorf("%s: missing placeholder: %q", format, got)
+			}
+		}
+	}
+}
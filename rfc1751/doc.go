@@ -0,0 +1,8 @@
+// Package rfc1751 implements the human-readable encoding of
+// 64-bit keys as six short English words, as specified by RFC
+// 1751 and used by S/KEY and some key backup schemes.
+//
+// Dictionary lookups scan the entire dictionary rather than
+// indexing it with secret data, so they run in constant time.
+// However, the encoding reveals the length of each word.
+package rfc1751
@@ -0,0 +1,199 @@
+package rfc1751
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+)
+
+var (
+	// ErrLength is returned when encoding data whose length is
+	// not a multiple of eight bytes or decoding a phrase whose
+	// length is not a multiple of six words.
+	ErrLength = errors.New("rfc1751: invalid length")
+	// ErrParity is returned when the parity bits of a group of
+	// six words do not match.
+	ErrParity = errors.New("rfc1751: parity mismatch")
+)
+
+// InvalidWordError is returned when a word is not part of the
+// dictionary. Its value is the index of the first invalid word.
+type InvalidWordError int
+
+func (e InvalidWordError) Error() string {
+	return "rfc1751: invalid word at index " + strconv.Itoa(int(e))
+}
+
+// packed is the dictionary with each word packed into the
+// low-order bytes of a uint32, first character first.
+var packed = func() (p [len(words)]uint32) {
+	for i, w := range words {
+		p[i] = pack(w)
+	}
+	return
+}()
+
+// pack packs a word of at most four characters into a uint32.
+func pack(w string) uint32 {
+	var v uint32
+	for i := 0; i < len(w); i++ {
+		v |= uint32(w[i]) << (24 - 8*i)
+	}
+	return v
+}
+
+// lookup returns the packed word for the 11-bit value v.
+//
+// lookup runs in constant time.
+func lookup(v uint32) uint32 {
+	var w uint32
+	for i, p := range packed {
+		m := -uint32(subtle.ConstantTimeEq(int32(i), int32(v)))
+		w |= p & m
+	}
+	return w
+}
+
+// index returns the 11-bit value of the packed word w and 1, or
+// zero and 0 if w is not in the dictionary.
+//
+// index runs in constant time.
+func index(w uint32) (uint32, int) {
+	var v uint32
+	var ok int
+	for i, p := range packed {
+		eq := subtle.ConstantTimeEq(int32(p), int32(w))
+		v |= uint32(i) & -uint32(eq)
+		ok |= eq
+	}
+	return v, ok
+}
+
+// parity returns the sum of the 2-bit pairs of key, modulo 4.
+func parity(key uint64) uint64 {
+	var p uint64
+	for i := 0; i < 64; i += 2 {
+		p += key >> i & 3
+	}
+	return p & 3
+}
+
+// AppendEncode appends the encoding of src, which must be
+// a multiple of eight bytes long, to dst and returns the extended
+// buffer.
+//
+// Each group of eight bytes is encoded as six space-separated
+// words.
+func AppendEncode(dst, src []byte) ([]byte, error) {
+	if len(src)%8 != 0 {
+		return dst, ErrLength
+	}
+	for i := 0; i < len(src); i += 8 {
+		key := binary.BigEndian.Uint64(src[i:])
+		// The 66-bit value is the key followed by the parity.
+		hi := key >> 62
+		lo := key<<2 | parity(key)
+		for j := 0; j < 6; j++ {
+			if i > 0 || j > 0 {
+				dst = append(dst, ' ')
+			}
+			s := uint(55 - 11*j)
+			v := uint32(lo>>s|hi<<(64-s)) & 2047
+			w := lookup(v)
+			var buf [4]byte
+			binary.BigEndian.PutUint32(buf[:], w)
+			// Words are one to four characters long; the
+			// unused bytes are zero.
+			n := 1 + int(buf[1]|-buf[1])>>7&1 +
+				int(buf[2]|-buf[2])>>7&1 +
+				int(buf[3]|-buf[3])>>7&1
+			dst = append(dst, buf[:n]...)
+			subtle.Wipe(buf[:])
+		}
+	}
+	return dst, nil
+}
+
+// EncodeToString returns the encoding of src, which must be
+// a multiple of eight bytes long.
+func EncodeToString(src []byte) (string, error) {
+	buf, err := AppendEncode(nil, src)
+	if err != nil {
+		return "", err
+	}
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s, nil
+}
+
+// DecodeString returns the bytes represented by the phrase s.
+//
+// Words are separated by white space and are case insensitive.
+// Like the RFC's reference implementation, '1', '0', and '5'
+// are read as 'L', 'O', and 'S'.
+//
+// If a word is not in the dictionary, DecodeString returns an
+// InvalidWordError. If the parity bits of a group of six words
+// do not match, it returns ErrParity.
+func DecodeString(s string) ([]byte, error) {
+	fields := strings.Fields(s)
+	if len(fields)%6 != 0 {
+		return nil, ErrLength
+	}
+	dst := make([]byte, len(fields)/6*8)
+
+	var bad alphabet.FirstError
+	var badParity int
+	for i := 0; i < len(fields); i += 6 {
+		var hi, lo uint64
+		for j, f := range fields[i : i+6] {
+			w, ok := packWord(f)
+			v, found := index(w)
+			ok &= found
+			hi = hi<<11 | lo>>53
+			lo = lo<<11 | uint64(v)
+			bad.Check(ok^1, i+j)
+		}
+		key := hi<<62 | lo>>2
+		badParity |= subtle.ConstantTimeEq(int32(parity(key)), int32(lo&3)) ^ 1
+		binary.BigEndian.PutUint64(dst[i/6*8:], key)
+	}
+	if bad.Failed() != 0 {
+		subtle.Wipe(dst)
+		return nil, InvalidWordError(bad.Index())
+	}
+	if badParity != 0 {
+		subtle.Wipe(dst)
+		return nil, ErrParity
+	}
+	return dst, nil
+}
+
+// packWord normalizes and packs the word f, returning 1 if it
+// could be in the dictionary.
+//
+// packWord runs in constant time for the length of f.
+func packWord(f string) (uint32, int) {
+	if len(f) > 4 {
+		return 0, 0
+	}
+	var w uint32
+	ok := 1
+	for i := 0; i < len(f); i++ {
+		c := f[i]
+		// A NUL would be indistinguishable from padding.
+		ok &= subtle.ConstantTimeByteEq(c, 0) ^ 1
+		lower := subtle.ConstantTimeByteLessOrEq('a', c) &
+			subtle.ConstantTimeByteLessOrEq(c, 'z')
+		c -= byte(lower << 5)
+		c = byte(subtle.ConstantTimeSelect(subtle.ConstantTimeByteEq(c, '1'), 'L', int(c)))
+		c = byte(subtle.ConstantTimeSelect(subtle.ConstantTimeByteEq(c, '0'), 'O', int(c)))
+		c = byte(subtle.ConstantTimeSelect(subtle.ConstantTimeByteEq(c, '5'), 'S', int(c)))
+		w |= uint32(c) << (24 - 8*i)
+	}
+	return w, ok
+}
@@ -0,0 +1,124 @@
+package rfc1751
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 1751.
+var vectors = []struct {
+	hex, words string
+}{
+	{"eb33f77ee73d4053", "TIDE ITCH SLOW REIN RULE MOT"},
+	{"ccac2aed591056be4f90fd441c534766", "RASH BUSH MILK LOOK BAD BRIM AVID GAFF BAIT ROT POD LOVE"},
+	{"eff81f9bfbc65350920cdd7416de8009", "TROD MUTE TAIL WARM CHAR KONG HAAG CITY BORE O TEAL AWL"},
+}
+
+// TestWords checks the structure of the dictionary: 571 words
+// of one to three characters followed by 1477 words of four
+// characters, each in sorted order.
+func TestWords(t *testing.T) {
+	const short = 571
+	for i, w := range words {
+		if (i < short) != (len(w) < 4) || len(w) == 0 || len(w) > 4 {
+			t.Fatalf("%d: unexpected word %q", i, w)
+		}
+	}
+	if !sort.StringsAreSorted(words[:short]) || !sort.StringsAreSorted(words[short:]) {
+		t.Fatal("dictionary is not sorted")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, v := range vectors {
+		src, _ := hex.DecodeString(v.hex)
+		got, err := EncodeToString(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v.words {
+			t.Fatalf("EncodeToString(%s): expected %q, got %q", v.hex, v.words, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, v := range vectors {
+		want, _ := hex.DecodeString(v.hex)
+		for _, s := range []string{
+			v.words,
+			strings.ToLower(v.words),
+			"  " + strings.ReplaceAll(v.words, " ", "\n\t") + "\n",
+		} {
+			got, err := DecodeString(s)
+			if err != nil {
+				t.Fatalf("DecodeString(%q): %v", s, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("DecodeString(%q): expected %x, got %x", s, want, got)
+			}
+		}
+	}
+
+	// '1', '0', and '5' are read as 'L', 'O', and 'S'.
+	got, err := DecodeString("TIDE ITCH 5L0W REIN RU1E M0T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := hex.DecodeString(vectors[0].hex); !bytes.Equal(got, want) {
+		t.Fatalf("expected %x, got %x", want, got)
+	}
+}
+
+func TestEncodeErr(t *testing.T) {
+	for _, n := range []int{1, 7, 9, 15} {
+		if _, err := EncodeToString(make([]byte, n)); err != ErrLength {
+			t.Fatalf("%d: expected %v, got %v", n, ErrLength, err)
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"TIDE ITCH SLOW REIN RULE", ErrLength},
+		{"TIDE ITCH SLOW REIN RULE MOT TIDE", ErrLength},
+		{"TIDE ITCH SLOW REIN RULE MOTT", InvalidWordError(5)},
+		{"TIDE ITCH SLOWS REIN RULE MOT", InvalidWordError(2)},
+		{"TIDE ITCH SLOW REIN ZZZ QQQ", InvalidWordError(4)},
+		{"TIDE IT\x00 SLOW REIN RULE MOT", InvalidWordError(1)},
+		{"TIDE ITCH SLOW REIN RULE MOT TIDE ITCH SLOW REIN RULE XYZ", InvalidWordError(11)},
+		{"TIDE ITCH SLOW REIN RULE MAT", ErrParity},
+	} {
+		_, err := DecodeString(tc.s)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("DecodeString(%q): expected %v, got %v", tc.s, tc.err, err)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n += 8 {
+		want := make([]byte, n)
+		rng.Read(want)
+		s, err := EncodeToString(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeString(s)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", s, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("expected %x, got %x", want, got)
+		}
+	}
+}
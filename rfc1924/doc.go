@@ -0,0 +1,13 @@
+// Package rfc1924 implements constant-time base85 encoding
+// using the alphabet from RFC 1924.
+//
+// Data is encoded in groups of four bytes, like git binary
+// patches and Python's base64.b85encode, without padding. The
+// final group may be shorter.
+//
+// RFC 1924 itself encodes a 128-bit IPv6 address as a single
+// 20-digit number. See FormatUint128 and ParseUint128.
+//
+// Every character is translated without secret-dependent
+// branches or table lookups.
+package rfc1924
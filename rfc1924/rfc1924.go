@@ -0,0 +1,224 @@
+package rfc1924
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+	"github.com/ericlagergren/subtle/internal/base85"
+)
+
+const encodeRFC1924 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+-;<=>?@^_`{|}~"
+
+var rfc1924 = alphabet.New(encodeRFC1924)
+
+// ErrLength is returned by ParseUint128 when the input is not
+// 20 characters long.
+var ErrLength = errors.New("rfc1924: invalid length")
+
+// CorruptInputError is returned when the input is malformed.
+// Its value is the offset of the first invalid byte, or the
+// offset of the first byte of a group that does not fit in 32
+// bits.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal rfc1924 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// EncodedLen returns the length of the encoding of n source
+// bytes.
+func EncodedLen(n int) int {
+	m := n / 4 * 5
+	if r := n % 4; r > 0 {
+		m += r + 1
+	}
+	return m
+}
+
+// DecodedLen returns the length of the bytes represented by n
+// encoded bytes.
+//
+// A final group of one character is invalid, so it does not
+// count towards the length.
+func DecodedLen(n int) int {
+	m := n / 5 * 4
+	if r := n % 5; r > 1 {
+		m += r - 1
+	}
+	return m
+}
+
+// Encode encodes src into EncodedLen(len(src)) bytes of dst.
+// As a convenience, it returns the number of bytes written to
+// dst, but this value is always EncodedLen(len(src)).
+//
+// Encode runs in constant time for the length of src.
+func Encode(dst, src []byte) int {
+	n := 0
+	for len(src) >= 4 {
+		base85.EncodeBlock(rfc1924, dst[n:], binary.BigEndian.Uint32(src))
+		src = src[4:]
+		n += 5
+	}
+	if len(src) > 0 {
+		// Encode the final fragment as if it were padded with
+		// zeros and discard the low digits.
+		var buf [5]byte
+		copy(buf[:], src)
+		base85.EncodeBlock(rfc1924, buf[:], binary.BigEndian.Uint32(buf[:]))
+		n += copy(dst[n:], buf[:len(src)+1])
+		subtle.Wipe(buf[:])
+	}
+	return n
+}
+
+// AppendEncode appends the encoding of src to dst and returns
+// the extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	ret, out := subtle.SliceForAppend(dst, EncodedLen(len(src)))
+	Encode(out, src)
+	return ret
+}
+
+// EncodeToString returns the encoding of src.
+func EncodeToString(src []byte) string {
+	buf := AppendEncode(nil, src)
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s
+}
+
+// Decode decodes src into DecodedLen(len(src)) bytes of dst,
+// returning the actual number of bytes written to dst.
+//
+// If src contains invalid data, Decode returns the number of
+// bytes decoded before the group containing the error and
+// a CorruptInputError.
+//
+// Decode runs in constant time for the length of src.
+func Decode(dst, src []byte) (int, error) {
+	if len(src)%5 == 1 {
+		return 0, CorruptInputError(len(src))
+	}
+	n := DecodedLen(len(src))
+	_ = dst[:n] // bounds check hint to compiler
+
+	var bad alphabet.FirstError
+	var i, j int
+	for ; i+5 <= len(src); i, j = i+5, j+4 {
+		v, idx, ok := base85.DecodeBlock(rfc1924, src[i:i+5])
+		binary.BigEndian.PutUint32(dst[j:], v)
+		bad.Check(ok^1, i+idx)
+	}
+	if r := len(src) - i; r > 0 {
+		// The short encoding truncated the value, so pad it
+		// with the highest digit to ensure the top bits are
+		// correct.
+		buf := [5]byte{'~', '~', '~', '~', '~'}
+		copy(buf[:], src[i:])
+		v, idx, ok := base85.DecodeBlock(rfc1924, buf[:])
+		var out [4]byte
+		binary.BigEndian.PutUint32(out[:], v)
+		copy(dst[j:], out[:r-1])
+		subtle.Wipe(buf[:])
+		subtle.Wipe(out[:])
+		bad.Check(ok^1, i+idx)
+	}
+	if bad.Failed() != 0 {
+		m := bad.Index() / 5 * 4
+		subtle.Wipe(dst[m:n])
+		return m, CorruptInputError(bad.Index())
+	}
+	return n, nil
+}
+
+// AppendDecode appends the bytes represented by src to dst and
+// returns the extended buffer.
+//
+// If the input is malformed, AppendDecode returns dst extended
+// by the bytes decoded before the error.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	ret, out := subtle.SliceForAppend(dst, DecodedLen(len(src)))
+	n, err := Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by s.
+func DecodeString(s string) ([]byte, error) {
+	return AppendDecode(nil, []byte(s))
+}
+
+// Uint128Len is the length of the encoding of a 128-bit value.
+const Uint128Len = 20
+
+// AppendUint128 appends the RFC 1924 encoding of the 128-bit
+// big-endian value v, like an IPv6 address, to dst and returns
+// the extended buffer.
+//
+// AppendUint128 runs in constant time.
+func AppendUint128(dst []byte, v [16]byte) []byte {
+	var limbs [4]uint32
+	for i := range limbs {
+		limbs[i] = binary.BigEndian.Uint32(v[i*4:])
+	}
+	ret, out := subtle.SliceForAppend(dst, Uint128Len)
+	// Division by the constant 85 compiles to a multiplication,
+	// so it does not depend on the data.
+	for i := len(out) - 1; i >= 0; i-- {
+		var r uint64
+		for j := range limbs {
+			cur := r<<32 | uint64(limbs[j])
+			limbs[j] = uint32(cur / 85)
+			r = cur % 85
+		}
+		out[i] = rfc1924.Encode(byte(r))
+	}
+	return ret
+}
+
+// FormatUint128 returns the RFC 1924 encoding of the 128-bit
+// big-endian value v.
+func FormatUint128(v [16]byte) string {
+	var buf [Uint128Len]byte
+	return string(AppendUint128(buf[:0], v))
+}
+
+// ParseUint128 decodes the 20-character RFC 1924 encoding of
+// a 128-bit big-endian value.
+//
+// It returns ErrLength if s is not 20 characters long and
+// a CorruptInputError if s contains an invalid character or
+// does not fit in 128 bits.
+//
+// ParseUint128 runs in constant time.
+func ParseUint128(s string) (v [16]byte, err error) {
+	if len(s) != Uint128Len {
+		return v, ErrLength
+	}
+	var limbs [4]uint32
+	var bad alphabet.FirstError
+	var over uint64 // carries out of the top limb
+	for i := 0; i < len(s); i++ {
+		d, ok := rfc1924.Decode(s[i])
+		carry := uint64(d)
+		for j := len(limbs) - 1; j >= 0; j-- {
+			t := uint64(limbs[j])*85 + carry
+			limbs[j] = uint32(t)
+			carry = t >> 32
+		}
+		over |= carry
+		bad.Check(ok^1, i)
+	}
+	// An overflow is reported at offset zero.
+	bad.Check(int((over|-over)>>63), 0)
+	if bad.Failed() != 0 {
+		return v, CorruptInputError(bad.Index())
+	}
+	for i := range limbs {
+		binary.BigEndian.PutUint32(v[i*4:], limbs[i])
+	}
+	return v, nil
+}
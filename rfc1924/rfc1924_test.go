@@ -0,0 +1,147 @@
+package rfc1924
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// Test vectors from Python's base64.b85encode.
+var vectors = []struct {
+	hex, encoded string
+}{
+	{"", ""},
+	{"00", "00"},
+	{"00000000", "00000"},
+	{"ffffffff", "|NsC0"},
+	{"68656c6c6f20776f726c642121", "Xk~0{Zy<MXa%^NFAp"},
+	{"00010203040506", "009C61O)~"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, v := range vectors {
+		src, _ := hex.DecodeString(v.hex)
+		if got := EncodeToString(src); got != v.encoded {
+			t.Fatalf("EncodeToString(%s): expected %q, got %q", v.hex, v.encoded, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, v := range vectors {
+		want, _ := hex.DecodeString(v.hex)
+		got, err := DecodeString(v.encoded)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", v.encoded, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("DecodeString(%q): expected %x, got %x", v.encoded, want, got)
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		src string
+		n   int
+		err error
+	}{
+		{"0", 0, CorruptInputError(1)},
+		{"000000", 0, CorruptInputError(6)},
+		{"00000\"0000", 4, CorruptInputError(5)},
+		{"000\"0", 0, CorruptInputError(3)},
+		{"00000|NsC1", 4, CorruptInputError(5)},
+		{"00000~~", 4, CorruptInputError(5)},
+		{"00000 ~", 4, CorruptInputError(5)},
+	} {
+		dst := make([]byte, DecodedLen(len(tc.src)))
+		n, err := Decode(dst, []byte(tc.src))
+		if n != tc.n || !errors.Is(err, tc.err) {
+			t.Fatalf("Decode(%q): expected (%d, %v), got (%d, %v)", tc.src, tc.n, tc.err, n, err)
+		}
+		if !bytes.Equal(dst[n:], make([]byte, len(dst)-n)) {
+			t.Fatalf("Decode(%q): remainder not wiped: %x", tc.src, dst[n:])
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		want := make([]byte, n)
+		rng.Read(want)
+		s := EncodeToString(want)
+		if len(s) != EncodedLen(n) {
+			t.Fatalf("expected length %d, got %d", EncodedLen(n), len(s))
+		}
+		if DecodedLen(len(s)) != n {
+			t.Fatalf("expected decoded length %d, got %d", n, DecodedLen(len(s)))
+		}
+		got, err := DecodeString(s)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", s, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("expected %x, got %x", want, got)
+		}
+	}
+}
+
+func TestUint128(t *testing.T) {
+	for _, tc := range []struct {
+		hex, encoded string
+	}{
+		// From RFC 1924: 1080:0:0:0:8:800:200C:417A.
+		{"108000000000000000080800200c417a", "4)+k&C#VzJ4br>0wv%Yp"},
+		{"00000000000000000000000000000000", "00000000000000000000"},
+		{"ffffffffffffffffffffffffffffffff", "=r54lj&NUUO~Hi%c2ym0"},
+	} {
+		var v [16]byte
+		hex.Decode(v[:], []byte(tc.hex))
+		if got := FormatUint128(v); got != tc.encoded {
+			t.Fatalf("FormatUint128(%s): expected %q, got %q", tc.hex, tc.encoded, got)
+		}
+		got, err := ParseUint128(tc.encoded)
+		if err != nil {
+			t.Fatalf("ParseUint128(%q): %v", tc.encoded, err)
+		}
+		if got != v {
+			t.Fatalf("ParseUint128(%q): expected %x, got %x", tc.encoded, v, got)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		var v [16]byte
+		rng.Read(v[:])
+		got, err := ParseUint128(FormatUint128(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v {
+			t.Fatalf("expected %x, got %x", v, got)
+		}
+	}
+}
+
+func TestParseUint128Err(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"", ErrLength},
+		{"4)+k&C#VzJ4br>0wv%Y", ErrLength},
+		{"4)+k&C#VzJ4br>0wv%Yp0", ErrLength},
+		{"4)+k&C#VzJ4br>0wv\"Yp", CorruptInputError(17)},
+		{"=r54lj&NUUO~Hi%c2ym1", CorruptInputError(0)},
+		{"~~~~~~~~~~~~~~~~~~~~", CorruptInputError(0)},
+		{"~~~~~~~~~~~~~~~~~~\"~", CorruptInputError(18)},
+	} {
+		_, err := ParseUint128(tc.s)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("ParseUint128(%q): expected %v, got %v", tc.s, tc.err, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package subtle
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 	"time"
@@ -66,3 +67,17 @@ func TestConstantTimeBigEndianLessOrEq(t *testing.T) {
 		}
 	}
 }
+
+func TestConstantTimeAppend(t *testing.T) {
+	src := []byte("hello, world")
+	for n := 0; n <= len(src); n++ {
+		dst := bytes.Repeat([]byte{'x'}, len(src)+4)
+		want := append(append([]byte(nil), src[:n]...), dst[n:]...)
+		if got := ConstantTimeAppend(dst, src, n); got != n {
+			t.Fatalf("#%d: expected %d, got %d", n, n, got)
+		}
+		if !bytes.Equal(dst, want) {
+			t.Fatalf("#%d: expected %q, got %q", n, want, dst)
+		}
+	}
+}
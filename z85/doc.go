@@ -0,0 +1,10 @@
+// Package z85 implements constant-time Z85 encoding as
+// specified by ZeroMQ RFC 32.
+//
+// Z85 is typically used for CurveZMQ keys, so every character
+// is translated without secret-dependent branches or table
+// lookups.
+//
+// Z85 only encodes data whose length is a multiple of four
+// bytes; there is no padding.
+package z85
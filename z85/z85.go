@@ -0,0 +1,133 @@
+package z85
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"github.com/ericlagergren/subtle"
+	"github.com/ericlagergren/subtle/internal/alphabet"
+	"github.com/ericlagergren/subtle/internal/base85"
+)
+
+const encodeZ85 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+var z85 = alphabet.New(encodeZ85)
+
+// ErrLength is returned when encoding data whose length is not
+// a multiple of four bytes or decoding data whose length is not
+// a multiple of five bytes.
+var ErrLength = errors.New("z85: invalid length")
+
+// CorruptInputError is returned when the input is malformed.
+// Its value is the offset of the first invalid byte, or the
+// offset of the first byte of a group of five characters that
+// does not fit in 32 bits.
+type CorruptInputError int64
+
+func (e CorruptInputError) Error() string {
+	return "illegal z85 data at input byte " + strconv.FormatInt(int64(e), 10)
+}
+
+// EncodedLen returns the length of the Z85 encoding of n source
+// bytes. n must be a multiple of four.
+func EncodedLen(n int) int {
+	return n / 4 * 5
+}
+
+// DecodedLen returns the length of the bytes represented by n
+// Z85 characters. n must be a multiple of five.
+func DecodedLen(n int) int {
+	return n / 5 * 4
+}
+
+// Encode encodes src into EncodedLen(len(src)) bytes of dst.
+// As a convenience, it returns the number of bytes written to
+// dst, but this value is always EncodedLen(len(src)).
+//
+// Encode returns ErrLength if len(src) is not a multiple of
+// four.
+func Encode(dst, src []byte) (int, error) {
+	if len(src)%4 != 0 {
+		return 0, ErrLength
+	}
+	n := 0
+	for len(src) > 0 {
+		base85.EncodeBlock(z85, dst[n:], binary.BigEndian.Uint32(src))
+		src = src[4:]
+		n += 5
+	}
+	return n, nil
+}
+
+// AppendEncode appends the Z85 encoding of src to dst and
+// returns the extended buffer.
+func AppendEncode(dst, src []byte) ([]byte, error) {
+	if len(src)%4 != 0 {
+		return dst, ErrLength
+	}
+	ret, out := subtle.SliceForAppend(dst, EncodedLen(len(src)))
+	Encode(out, src)
+	return ret, nil
+}
+
+// EncodeToString returns the Z85 encoding of src.
+func EncodeToString(src []byte) (string, error) {
+	buf, err := AppendEncode(nil, src)
+	if err != nil {
+		return "", err
+	}
+	s := string(buf)
+	subtle.Wipe(buf)
+	return s, nil
+}
+
+// Decode decodes src into DecodedLen(len(src)) bytes of dst,
+// returning the actual number of bytes written to dst.
+//
+// If src contains invalid data, Decode returns the number of
+// bytes decoded before the group containing the error and
+// a CorruptInputError. Decode returns ErrLength if len(src) is
+// not a multiple of five.
+//
+// Decode runs in constant time for the length of src.
+func Decode(dst, src []byte) (int, error) {
+	if len(src)%5 != 0 {
+		return 0, ErrLength
+	}
+	_ = dst[:DecodedLen(len(src))] // bounds check hint to compiler
+
+	var bad alphabet.FirstError
+	for i := 0; i < len(src); i += 5 {
+		v, idx, ok := base85.DecodeBlock(z85, src[i:i+5])
+		binary.BigEndian.PutUint32(dst[i/5*4:], v)
+		bad.Check(ok^1, i+idx)
+	}
+	n := DecodedLen(len(src))
+	if bad.Failed() != 0 {
+		m := bad.Index() / 5 * 4
+		subtle.Wipe(dst[m:n])
+		return m, CorruptInputError(bad.Index())
+	}
+	return n, nil
+}
+
+// AppendDecode appends the bytes represented by the Z85 data src
+// to dst and returns the extended buffer.
+//
+// If the input is malformed, AppendDecode returns dst extended
+// by the bytes decoded before the error.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	if len(src)%5 != 0 {
+		return dst, ErrLength
+	}
+	ret, out := subtle.SliceForAppend(dst, DecodedLen(len(src)))
+	n, err := Decode(out, src)
+	return ret[:len(dst)+n], err
+}
+
+// DecodeString returns the bytes represented by the Z85 string
+// s.
+func DecodeString(s string) ([]byte, error) {
+	return AppendDecode(nil, []byte(s))
+}
@@ -0,0 +1,101 @@
+package z85
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+var vectors = []struct {
+	hex, encoded string
+}{
+	{"", ""},
+	// From ZeroMQ RFC 32.
+	{"864fd26fb559f75b", "HelloWorld"},
+	// From the CurveZMQ test suite.
+	{"8e0bdd697628b91d8f245587ee95c5b04d48963f79259877b49cd9063aead3b7", "JTKVSB%%)wK0E.X)V>+}o?pNmC{O&4W4b!Ni{Lh6"},
+	{"00000000", "00000"},
+	{"ffffffff", "%nSc0"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, v := range vectors {
+		src, _ := hex.DecodeString(v.hex)
+		got, err := EncodeToString(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v.encoded {
+			t.Fatalf("EncodeToString(%s): expected %q, got %q", v.hex, v.encoded, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, v := range vectors {
+		want, _ := hex.DecodeString(v.hex)
+		got, err := DecodeString(v.encoded)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", v.encoded, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("DecodeString(%q): expected %x, got %x", v.encoded, want, got)
+		}
+	}
+}
+
+func TestEncodeErr(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 31} {
+		if _, err := EncodeToString(make([]byte, n)); err != ErrLength {
+			t.Fatalf("%d: expected %v, got %v", n, ErrLength, err)
+		}
+	}
+}
+
+func TestDecodeErr(t *testing.T) {
+	for _, tc := range []struct {
+		src string
+		n   int
+		err error
+	}{
+		{"0", 0, ErrLength},
+		{"HelloWorld0", 0, ErrLength},
+		{"Hello\"orld", 4, CorruptInputError(5)},
+		{"Hel\x00oWorld", 0, CorruptInputError(3)},
+		{"Hello%nSc1World", 4, CorruptInputError(5)},
+		{"HelloWor~d~orld", 4, CorruptInputError(8)},
+	} {
+		dst := make([]byte, DecodedLen(len(tc.src)))
+		n, err := Decode(dst, []byte(tc.src))
+		if n != tc.n || !errors.Is(err, tc.err) {
+			t.Fatalf("Decode(%q): expected (%d, %v), got (%d, %v)", tc.src, tc.n, tc.err, n, err)
+		}
+		if !bytes.Equal(dst[n:], make([]byte, len(dst)-n)) {
+			t.Fatalf("Decode(%q): remainder not wiped: %x", tc.src, dst[n:])
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 400; n += 4 {
+		want := make([]byte, n)
+		rng.Read(want)
+		s, err := EncodeToString(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s) != EncodedLen(n) {
+			t.Fatalf("expected length %d, got %d", EncodedLen(n), len(s))
+		}
+		got, err := DecodeString(s)
+		if err != nil {
+			t.Fatalf("DecodeString(%q): %v", s, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("expected %x, got %x", want, got)
+		}
+	}
+}